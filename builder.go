@@ -0,0 +1,388 @@
+package iabtcfv2
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TCDataBuilder builds a TCData with consistent derived fields
+// Its methods return the builder so that calls can be chained, and values are only checked by Build
+type TCDataBuilder struct {
+	core            CoreString
+	specialFeatures *IDSet
+	purposes        *IDSet
+	purposesLI      *IDSet
+	vendors         *IDSet
+	vendorsLI       *IDSet
+	restrictions    map[pubRestrictionKey]*IDSet
+	disclosed       *IDSet
+	allowed         *IDSet
+	publisherTC     *PublisherTC
+	invalidID       *builderID
+}
+
+// An id that was added to field but can't be encoded, reported by Build
+type builderID struct {
+	field string
+	id    int
+}
+
+// Returns a builder of a TC String created now, with consent language "EN", publisher country code "AA"
+// and TCF policy version 2
+func NewTCDataBuilder() *TCDataBuilder {
+	now := time.Now().Truncate(time.Second / decisecondsPerSecond).UTC()
+	return &TCDataBuilder{
+		core: CoreString{
+			Version:          int(TcfVersion2),
+			Created:          now,
+			LastUpdated:      now,
+			ConsentLanguage:  "EN",
+			TcfPolicyVersion: 2,
+			PublisherCC:      "AA",
+		},
+		specialFeatures: NewIDSet(),
+		purposes:        NewIDSet(),
+		purposesLI:      NewIDSet(),
+		vendors:         NewIDSet(),
+		vendorsLI:       NewIDSet(),
+		restrictions:    make(map[pubRestrictionKey]*IDSet),
+	}
+}
+
+// Sets CmpId and CmpVersion
+func (b *TCDataBuilder) WithCMP(id int, version int) *TCDataBuilder {
+	b.core.CmpId = id
+	b.core.CmpVersion = version
+	return b
+}
+
+// Sets VendorListVersion
+func (b *TCDataBuilder) WithGVLVersion(version int) *TCDataBuilder {
+	b.core.VendorListVersion = version
+	return b
+}
+
+// Sets TcfPolicyVersion
+func (b *TCDataBuilder) WithPolicyVersion(version int) *TCDataBuilder {
+	b.core.TcfPolicyVersion = version
+	return b
+}
+
+// Sets ConsentScreen
+func (b *TCDataBuilder) WithConsentScreen(screen int) *TCDataBuilder {
+	b.core.ConsentScreen = screen
+	return b
+}
+
+// Sets ConsentLanguage, as an ISO 639-1 two-letter code
+func (b *TCDataBuilder) WithConsentLanguage(language string) *TCDataBuilder {
+	b.core.ConsentLanguage = strings.ToUpper(language)
+	return b
+}
+
+// Sets PublisherCC, as an ISO 3166-1 alpha-2 code
+func (b *TCDataBuilder) WithPublisherCC(cc string) *TCDataBuilder {
+	b.core.PublisherCC = strings.ToUpper(cc)
+	return b
+}
+
+// Sets both Created and LastUpdated
+func (b *TCDataBuilder) WithCreated(t time.Time) *TCDataBuilder {
+	b.core.Created = t
+	b.core.LastUpdated = t
+	return b
+}
+
+// Sets LastUpdated
+func (b *TCDataBuilder) WithLastUpdated(t time.Time) *TCDataBuilder {
+	b.core.LastUpdated = t
+	return b
+}
+
+// Sets IsServiceSpecific
+func (b *TCDataBuilder) ServiceSpecific(v bool) *TCDataBuilder {
+	b.core.IsServiceSpecific = v
+	return b
+}
+
+// Sets UseNonStandardTexts
+func (b *TCDataBuilder) NonStandardTexts(v bool) *TCDataBuilder {
+	b.core.UseNonStandardTexts = v
+	return b
+}
+
+// Sets PurposeOneTreatment
+func (b *TCDataBuilder) PurposeOneTreatment(v bool) *TCDataBuilder {
+	b.core.PurposeOneTreatment = v
+	return b
+}
+
+// Adds special feature ids to SpecialFeatureOptIns
+func (b *TCDataBuilder) OptInSpecialFeatures(ids ...int) *TCDataBuilder {
+	b.addIDs(fieldSpecialFeatureOptIns, b.specialFeatures, ids)
+	return b
+}
+
+// Adds purpose ids to PurposesConsent
+func (b *TCDataBuilder) ConsentPurposes(ids ...int) *TCDataBuilder {
+	b.addIDs(fieldPurposesConsent, b.purposes, ids)
+	return b
+}
+
+// Adds purpose ids to PurposesLITransparency
+func (b *TCDataBuilder) LIPurposes(ids ...int) *TCDataBuilder {
+	b.addIDs(fieldPurposesLITransparency, b.purposesLI, ids)
+	return b
+}
+
+// Removes purpose ids from PurposesLITransparency, user objected to their legitimate interest
+func (b *TCDataBuilder) ObjectLIPurposes(ids ...int) *TCDataBuilder {
+	removeIDs(b.purposesLI, ids)
+	return b
+}
+
+// Adds vendor ids to VendorsConsent
+func (b *TCDataBuilder) ConsentVendors(ids ...int) *TCDataBuilder {
+	b.addIDs(fieldVendorsConsent, b.vendors, ids)
+	return b
+}
+
+// Adds vendor ids to VendorsLITransparency
+func (b *TCDataBuilder) LIVendors(ids ...int) *TCDataBuilder {
+	b.addIDs(fieldVendorsLITransparency, b.vendorsLI, ids)
+	return b
+}
+
+// Removes vendor ids from VendorsLITransparency, user objected to their legitimate interest
+func (b *TCDataBuilder) ObjectLIVendors(ids ...int) *TCDataBuilder {
+	removeIDs(b.vendorsLI, ids)
+	return b
+}
+
+// Adds vendor ids to the publisher restriction of restrictionType for purpose id
+// Restrictions are grouped by purpose and restriction type, and their range entries are computed by Build
+func (b *TCDataBuilder) AddPubRestriction(purposeId int, restrictionType RestrictionType, vendorIds ...int) *TCDataBuilder {
+	k := pubRestrictionKey{purposeId: purposeId, restrictionType: restrictionType}
+	if b.restrictions[k] == nil {
+		b.restrictions[k] = NewIDSet()
+	}
+	b.addIDs(fieldPubRestrictions, b.restrictions[k], vendorIds)
+	return b
+}
+
+// Adds vendor ids to the Disclosed Vendors segment
+func (b *TCDataBuilder) DiscloseVendors(ids ...int) *TCDataBuilder {
+	if b.disclosed == nil {
+		b.disclosed = NewIDSet()
+	}
+	b.addIDs(fieldDisclosedVendors, b.disclosed, ids)
+	return b
+}
+
+// Adds vendor ids to the Allowed Vendors segment
+func (b *TCDataBuilder) AllowVendors(ids ...int) *TCDataBuilder {
+	if b.allowed == nil {
+		b.allowed = NewIDSet()
+	}
+	b.addIDs(fieldAllowedVendors, b.allowed, ids)
+	return b
+}
+
+// Sets the Publisher TC segment
+// SegmentType is set by Build, and NumCustomPurposes is raised to the highest custom purpose id if needed
+func (b *TCDataBuilder) WithPublisherTC(p *PublisherTC) *TCDataBuilder {
+	b.publisherTC = p
+	return b
+}
+
+// Returns the TCData, or an error wrapping ErrInvalidValue if a value can't be encoded in a TC String
+// Vendors are set with the shortest encoding, and all entry counts are computed
+func (b *TCDataBuilder) Build() (*TCData, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+
+	core := b.core
+	core.SpecialFeatureOptIns = idSetBitField(b.specialFeatures)
+	core.PurposesConsent = idSetBitField(b.purposes)
+	core.PurposesLITransparency = idSetBitField(b.purposesLI)
+	core.SetVendorsConsent(b.vendors.IDs()...)
+	core.SetVendorsLITransparency(b.vendorsLI.IDs()...)
+
+	keys := make([]pubRestrictionKey, 0, len(b.restrictions))
+	for k, s := range b.restrictions {
+		if s.Len() > 0 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].purposeId != keys[j].purposeId {
+			return keys[i].purposeId < keys[j].purposeId
+		}
+		return keys[i].restrictionType < keys[j].restrictionType
+	})
+	core.PubRestrictions = nil
+	for _, k := range keys {
+		entries := b.restrictions[k].Ranges()
+		core.PubRestrictions = append(core.PubRestrictions, &PubRestriction{
+			PurposeId:       k.purposeId,
+			RestrictionType: k.restrictionType,
+			NumEntries:      len(entries),
+			RangeEntries:    entries,
+			VendorSet:       newIDSetFromEncoding(true, nil, entries),
+		})
+	}
+	core.NumPubRestrictions = len(core.PubRestrictions)
+
+	t := &TCData{CoreString: &core}
+
+	if b.disclosed != nil {
+		t.DisclosedVendors = &DisclosedVendors{SegmentType: int(SegmentTypeDisclosedVendors)}
+		t.DisclosedVendors.SetDisclosedVendors(b.disclosed.IDs()...)
+	}
+	if b.allowed != nil {
+		t.AllowedVendors = &AllowedVendors{SegmentType: int(SegmentTypeAllowedVendors)}
+		t.AllowedVendors.SetAllowedVendors(b.allowed.IDs()...)
+	}
+	if b.publisherTC != nil {
+		p := *b.publisherTC
+		p.SegmentType = int(SegmentTypePublisherTC)
+		for _, m := range []map[int]bool{p.CustomPurposesConsent, p.CustomPurposesLITransparency} {
+			for id, v := range m {
+				if v && id > p.NumCustomPurposes {
+					p.NumCustomPurposes = id
+				}
+			}
+		}
+		t.PublisherTC = &p
+	}
+
+	return t, nil
+}
+
+func (b *TCDataBuilder) validate() error {
+	c := &b.core
+	for _, f := range []struct {
+		field string
+		value int
+		bits  uint
+	}{
+		{"CmpId", c.CmpId, bitsCmpId},
+		{"CmpVersion", c.CmpVersion, bitsCmpVersion},
+		{"ConsentScreen", c.ConsentScreen, bitsConsentScreen},
+		{"VendorListVersion", c.VendorListVersion, bitsVendorListVersion},
+		{"TcfPolicyVersion", c.TcfPolicyVersion, bitsTcfPolicyVersion},
+	} {
+		if f.value < 0 || f.value >= 1<<f.bits {
+			return fmt.Errorf("%s: %w: %d doesn't fit in %d bits", f.field, ErrInvalidValue, f.value, f.bits)
+		}
+	}
+
+	for _, f := range []struct {
+		field string
+		value string
+	}{
+		{"ConsentLanguage", c.ConsentLanguage},
+		{"PublisherCC", c.PublisherCC},
+	} {
+		if len(f.value) != 2 || f.value[0] < 'A' || f.value[0] > 'Z' || f.value[1] < 'A' || f.value[1] > 'Z' {
+			return fmt.Errorf("%s: %w: %q isn't a two-letter code", f.field, ErrInvalidValue, f.value)
+		}
+	}
+
+	if c.Created.Before(time.Unix(0, 0)) {
+		return fmt.Errorf("Created: %w: %s before 1970", ErrInvalidValue, c.Created)
+	}
+	if c.LastUpdated.Before(c.Created) {
+		return fmt.Errorf("LastUpdated: %w: %s before created %s", ErrInvalidValue, c.LastUpdated, c.Created)
+	}
+
+	if i := b.invalidID; i != nil {
+		return fmt.Errorf("%s: %w: id %d below 1", i.field, ErrInvalidValue, i.id)
+	}
+
+	for _, f := range []struct {
+		field string
+		ids   *IDSet
+		max   int
+	}{
+		{fieldSpecialFeatureOptIns, b.specialFeatures, bitsSpecialFeatureOptIns},
+		{fieldPurposesConsent, b.purposes, bitsPurposesConsent},
+		{fieldPurposesLITransparency, b.purposesLI, bitsPurposesLITransparency},
+		{fieldVendorsConsent, b.vendors, 1<<bitsVendorId - 1},
+		{fieldVendorsLITransparency, b.vendorsLI, 1<<bitsVendorId - 1},
+		{fieldDisclosedVendors, b.disclosed, 1<<bitsVendorId - 1},
+		{fieldAllowedVendors, b.allowed, 1<<bitsVendorId - 1},
+	} {
+		if max := f.ids.Max(); max > f.max {
+			return fmt.Errorf("%s: %w: id %d above %d", f.field, ErrInvalidValue, max, f.max)
+		}
+	}
+
+	vendorRestrictions := make(map[int]*IDSet)
+	for k, s := range b.restrictions {
+		if k.purposeId < 1 || k.purposeId >= 1<<bitsPubRestrictionsEntryPurposeId {
+			return fmt.Errorf("%s: %w: purpose id %d", fieldPubRestrictions, ErrInvalidValue, k.purposeId)
+		}
+		if k.restrictionType < RestrictionTypeNotAllowed || k.restrictionType >= RestrictionTypeUndefined {
+			return fmt.Errorf("%s: %w: restriction type %d", fieldPubRestrictions, ErrInvalidValue, int(k.restrictionType))
+		}
+		if max := s.Max(); max >= 1<<bitsVendorId {
+			return fmt.Errorf("%s: %w: vendor id %d", fieldPubRestrictions, ErrInvalidValue, max)
+		}
+		// A vendor can only have one restriction type for a purpose
+		if both := vendorRestrictions[k.purposeId].Intersect(s); both.Len() > 0 {
+			return fmt.Errorf("%s: %w: vendor %d has several restrictions for purpose %d", fieldPubRestrictions, ErrInvalidValue, both.IDs()[0], k.purposeId)
+		}
+		vendorRestrictions[k.purposeId] = vendorRestrictions[k.purposeId].Union(s)
+	}
+
+	if p := b.publisherTC; p != nil {
+		for _, f := range []struct {
+			field string
+			ids   map[int]bool
+			max   int
+		}{
+			{fieldPubPurposesConsent, p.PubPurposesConsent, bitsPubPurposesConsent},
+			{fieldPubPurposesLITransparency, p.PubPurposesLITransparency, bitsPubPurposesLITransparency},
+			{fieldCustomPurposesConsent, p.CustomPurposesConsent, 1<<bitsNumCustomPurposes - 1},
+			{fieldCustomPurposesLITransparency, p.CustomPurposesLITransparency, 1<<bitsNumCustomPurposes - 1},
+		} {
+			if max := newIDSetFromEncoding(false, f.ids, nil).Max(); max > f.max {
+				return fmt.Errorf("%s: %w: id %d above %d", f.field, ErrInvalidValue, max, f.max)
+			}
+		}
+		if p.NumCustomPurposes < 0 || p.NumCustomPurposes >= 1<<bitsNumCustomPurposes {
+			return fmt.Errorf("NumCustomPurposes: %w: %d doesn't fit in %d bits", ErrInvalidValue, p.NumCustomPurposes, bitsNumCustomPurposes)
+		}
+	}
+
+	return nil
+}
+
+// Adds ids to s, keeping the first one below 1 to be reported by Build
+func (b *TCDataBuilder) addIDs(field string, s *IDSet, ids []int) {
+	for _, id := range ids {
+		if id < 1 && b.invalidID == nil {
+			b.invalidID = &builderID{field: field, id: id}
+		}
+		s.Add(id)
+	}
+}
+
+func removeIDs(s *IDSet, ids []int) {
+	for _, id := range ids {
+		s.Remove(id)
+	}
+}
+
+func idSetBitField(s *IDSet) map[int]bool {
+	m := make(map[int]bool, s.Len())
+	s.Iterate(func(id int) bool {
+		m[id] = true
+		return true
+	})
+	return m
+}
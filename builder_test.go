@@ -0,0 +1,94 @@
+package iabtcfv2
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTCDataBuilder(t *testing.T) {
+	data, err := NewTCDataBuilder().
+		WithCMP(92, 3).
+		WithGVLVersion(150).
+		WithConsentLanguage("fr").
+		WithPublisherCC("fr").
+		WithCreated(timeFromDeciSeconds(16431552000)).
+		OptInSpecialFeatures(1).
+		ConsentPurposes(1, 2, 3).
+		LIPurposes(2, 7, 8).
+		ObjectLIPurposes(8).
+		ConsentVendors(1, 2, 3, 4, 5, 755).
+		LIVendors(8, 9).
+		ObjectLIVendors(9).
+		AddPubRestriction(2, RestrictionTypeRequireConsent, 8, 9, 10).
+		AddPubRestriction(2, RestrictionTypeNotAllowed, 755).
+		AddPubRestriction(2, RestrictionTypeRequireConsent, 11).
+		DiscloseVendors(1, 2, 755).
+		WithPublisherTC(&PublisherTC{PubPurposesConsent: map[int]bool{1: true}, CustomPurposesConsent: map[int]bool{3: true}}).
+		Build()
+	if err != nil {
+		t.Errorf("TCData should be built without error: %s", err)
+		return
+	}
+
+	c := data.CoreString
+	if c.Version != 2 || c.CmpId != 92 || c.CmpVersion != 3 || c.VendorListVersion != 150 || c.ConsentLanguage != "FR" || c.PublisherCC != "FR" {
+		t.Errorf("Unexpected core string: %+v", c)
+	}
+	if !c.IsPurposeLIAllowed(7) || c.IsPurposeLIAllowed(8) || !c.IsVendorLIAllowed(8) || c.IsVendorLIAllowed(9) {
+		t.Errorf("Objected legitimate interests should be removed")
+	}
+	if c.MaxVendorId != 755 || !c.IsRangeEncoding || c.NumEntries != 2 {
+		t.Errorf("Vendors should be set with the shortest encoding: %d %v %d", c.MaxVendorId, c.IsRangeEncoding, c.NumEntries)
+	}
+	if c.NumPubRestrictions != 2 || c.PubRestrictions[0].RestrictionType != RestrictionTypeNotAllowed ||
+		c.PubRestrictions[1].NumEntries != 1 || c.PubRestrictions[1].RangeEntries[0].EndVendorID != 11 {
+		t.Errorf("Publisher restrictions should be grouped by purpose and restriction type: %+v", c.PubRestrictions)
+	}
+	if data.AllowedVendors != nil || !data.DisclosedVendors.IsVendorDisclosed(755) {
+		t.Errorf("Only requested segments should be added")
+	}
+	if data.PublisherTC.SegmentType != int(SegmentTypePublisherTC) || data.PublisherTC.NumCustomPurposes != 3 {
+		t.Errorf("Publisher TC derived fields should be computed: %+v", data.PublisherTC)
+	}
+
+	decoded, err := Decode(data.ToTCString())
+	if err != nil {
+		t.Errorf("Built TC String should be decoded without error: %s", err)
+		return
+	}
+	if d := Diff(data, decoded); !d.IsEmpty() {
+		t.Errorf("Built TC String should be decoded as built:\n%s", d)
+	}
+}
+
+func TestTCDataBuilderErrors(t *testing.T) {
+	builders := map[string]*TCDataBuilder{
+		"cmp id":            NewTCDataBuilder().WithCMP(4096, 1),
+		"consent language":  NewTCDataBuilder().WithConsentLanguage("FRA"),
+		"publisher cc":      NewTCDataBuilder().WithPublisherCC("F1"),
+		"last updated":      NewTCDataBuilder().WithCreated(timeFromDeciSeconds(16431552000)).WithLastUpdated(timeFromDeciSeconds(16431551990)),
+		"created":           NewTCDataBuilder().WithCreated(time.Unix(-10, 0)),
+		"purpose":           NewTCDataBuilder().ConsentPurposes(25),
+		"purpose below 1":   NewTCDataBuilder().ConsentPurposes(-1),
+		"special feature":   NewTCDataBuilder().OptInSpecialFeatures(13),
+		"vendor":            NewTCDataBuilder().ConsentVendors(65536),
+		"vendor below 1":    NewTCDataBuilder().ConsentVendors(0),
+		"restricted vendor": NewTCDataBuilder().AddPubRestriction(1, RestrictionTypeNotAllowed, 0),
+		"restriction type":  NewTCDataBuilder().AddPubRestriction(1, RestrictionTypeUndefined, 1),
+		"restriction twice": NewTCDataBuilder().AddPubRestriction(2, RestrictionTypeNotAllowed, 1, 2).AddPubRestriction(2, RestrictionTypeRequireLI, 2),
+		"custom purpose":    NewTCDataBuilder().WithPublisherTC(&PublisherTC{CustomPurposesConsent: map[int]bool{64: true}}),
+	}
+
+	for name, b := range builders {
+		if _, err := b.Build(); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("Invalid %s should return ErrInvalidValue: %v", name, err)
+		}
+	}
+
+	_, err := NewTCDataBuilder().WithCreated(time.Unix(-10, 0)).Build()
+	if err == nil || !strings.HasPrefix(err.Error(), "Created:") {
+		t.Errorf("Created before 1970 should be reported on Created: %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package iabtcfv2
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Number of entries of a Cache when CacheOptions.MaxEntries isn't set
+const DefaultCacheMaxEntries = 10000
+
+type CacheOptions struct {
+	// Maximum number of TC Strings kept, the least recently used are evicted first
+	MaxEntries int
+	// Duration after which an entry is decoded again, 0 keeps entries until they are evicted
+	TTL time.Duration
+}
+
+type CacheStats struct {
+	Hits        uint64
+	Misses      uint64
+	Evictions   uint64
+	Expirations uint64
+}
+
+// Cache keeps the result of Decode for recently seen TC Strings, including errors of malformed strings
+// It is safe for concurrent use, and the TCData it returns are shared between callers:
+// they must be treated as read-only
+type Cache struct {
+	opts    CacheOptions
+	now     func() time.Time
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+	stats   CacheStats
+}
+
+type cacheEntry struct {
+	tcString string
+	data     *TCData
+	err      error
+	expires  time.Time
+}
+
+func NewCache(opts CacheOptions) *Cache {
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultCacheMaxEntries
+	}
+
+	return &Cache{
+		opts:    opts,
+		now:     time.Now,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Returns the result of Decode for tcString, decoding it only if it isn't cached or has expired
+func (c *Cache) Get(tcString string) (*TCData, error) {
+	if e, ok := c.lookup(tcString); ok {
+		return e.data, e.err
+	}
+
+	// Decoding is done outside of the lock, concurrent misses on the same string may decode it twice
+	data, err := Decode(tcString)
+	c.add(tcString, data, err)
+	return data, err
+}
+
+// Returns the statistics of the cache since its creation
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Returns the number of TC Strings in the cache
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Removes all entries from the cache
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Init()
+	c.entries = make(map[string]*list.Element)
+}
+
+func (c *Cache) lookup(tcString string) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[tcString]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+
+	e := el.Value.(*cacheEntry)
+	if c.opts.TTL > 0 && !c.now().Before(e.expires) {
+		c.remove(el)
+		c.stats.Expirations++
+		c.stats.Misses++
+		return nil, false
+	}
+
+	c.lru.MoveToFront(el)
+	c.stats.Hits++
+	return e, true
+}
+
+func (c *Cache) add(tcString string, data *TCData, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := &cacheEntry{tcString: tcString, data: data, err: err}
+	if c.opts.TTL > 0 {
+		e.expires = c.now().Add(c.opts.TTL)
+	}
+
+	if el, ok := c.entries[tcString]; ok {
+		el.Value = e
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	c.entries[tcString] = c.lru.PushFront(e)
+	for c.lru.Len() > c.opts.MaxEntries {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *Cache) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*cacheEntry).tcString)
+}
@@ -0,0 +1,112 @@
+package iabtcfv2
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	c := NewCache(CacheOptions{MaxEntries: 2})
+
+	a := "CPTZZYAPTZZYABcABCENAgCMAPzAAEPAAAqIDaQBQAMgAgABqAR0A2gDaQAwAMgAgANoAAA"
+	b := "CPTZZYAPTZZYABcABCENAgCMAPzAAEPAAAqIDaQBQAMgAgABqAR0A2gDaQAwAMgAgANoAAA.IDaQBQAMgAgABqAR0A2g"
+
+	first, err := c.Get(a)
+	if err != nil {
+		t.Errorf("TC String should be decoded without error: %s", err)
+		return
+	}
+
+	second, _ := c.Get(a)
+	if first != second {
+		t.Errorf("Cached TCData should be returned")
+	}
+
+	_, err = c.Get("AAAA")
+	if !errors.Is(err, ErrTruncated) {
+		t.Errorf("Malformed TC String should return its decoding error: %v", err)
+	}
+	_, err = c.Get("AAAA")
+	if !errors.Is(err, ErrTruncated) {
+		t.Errorf("Cached error should be returned: %v", err)
+	}
+
+	// a was used less recently than "AAAA" and is evicted
+	if _, err := c.Get(b); err != nil {
+		t.Errorf("TC String should be decoded without error: %s", err)
+	}
+	if c.Len() != 2 {
+		t.Errorf("Cache should be bounded to 2 entries: %d", c.Len())
+	}
+
+	if third, _ := c.Get(a); third == first {
+		t.Errorf("Evicted TC String should be decoded again")
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 2 || stats.Misses != 4 || stats.Evictions != 2 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	c.Purge()
+	if c.Len() != 0 {
+		t.Errorf("Cache should be empty after purge")
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := NewCache(CacheOptions{TTL: time.Minute})
+	c.now = func() time.Time { return now }
+
+	str := "CPTZZYAPTZZYABcABCENAgCMAPzAAEPAAAqIDaQBQAMgAgABqAR0A2gDaQAwAMgAgANoAAA"
+	first, _ := c.Get(str)
+
+	now = now.Add(59 * time.Second)
+	if second, _ := c.Get(str); second != first {
+		t.Errorf("TC String should be cached before TTL")
+	}
+
+	now = now.Add(time.Second)
+	if third, _ := c.Get(str); third == first {
+		t.Errorf("TC String should be decoded again after TTL")
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Expirations != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestCacheConcurrency(t *testing.T) {
+	c := NewCache(CacheOptions{MaxEntries: 3})
+	strs := []string{
+		"CPTZZYAPTZZYABcABCENAgCMAPzAAEPAAAqIDaQBQAMgAgABqAR0A2gDaQAwAMgAgANoAAA",
+		"CPTZZYAPTZZYABcABCENAgCMAPzAAEPAAAqIDaQBQAMgAgABqAR0A2gDaQAwAMgAgANoAAA.IDaQBQAMgAgABqAR0A2g",
+		"CPTZZYAPTZZYABcABCENAgCMAPzAAEPAAAqIDaQBQAMgAgABqAR0A2gDaQAwAMgAgANoAAA.QDaQAgAMwAgADUA",
+		"CPTZZYAPTZZYABcABCENAgCMAPzAAEPAAAqIDaQBQAMgAgABqAR0A2gDaQAwAMgAgANoAAA.eEAAAAAAAUA",
+		"AAAA",
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				data, err := c.Get(strs[(i+j)%len(strs)])
+				if err == nil && data.CoreString.CmpId != 92 {
+					t.Errorf("Cached TCData should be decoded: %d", data.CoreString.CmpId)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	stats := c.Stats()
+	if stats.Hits+stats.Misses != 8*200 || c.Len() > 3 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
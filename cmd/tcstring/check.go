@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/SirDataFR/iabtcfv2"
+)
+
+func runCheck(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	vendorId := fs.Int("vendor", 0, "vendor id")
+	purposes := fs.String("purposes", "", "comma separated purpose ids")
+	li := fs.Bool("li", false, "check legitimate interest instead of consent")
+	flexible := fs.Bool("flexible", false, "allow the other legal basis in accordance with publisher restrictions")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *vendorId <= 0 {
+		return errors.New("missing -vendor")
+	}
+	if *purposes == "" {
+		return errors.New("missing -purposes")
+	}
+
+	purposeIds, err := parseIDs(*purposes)
+	if err != nil {
+		return err
+	}
+
+	tcString, err := readTCString(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	data, err := iabtcfv2.Decode(tcString)
+	if err != nil {
+		return err
+	}
+
+	var allowed bool
+	switch {
+	case *flexible && *li:
+		allowed = data.IsVendorAllowedForFlexiblePurposesLI(*vendorId, purposeIds...)
+	case *flexible:
+		allowed = data.IsVendorAllowedForFlexiblePurposes(*vendorId, purposeIds...)
+	case *li:
+		allowed = data.IsVendorAllowedForPurposesLI(*vendorId, purposeIds...)
+	default:
+		allowed = data.IsVendorAllowedForPurposes(*vendorId, purposeIds...)
+	}
+
+	if !allowed {
+		fmt.Fprintf(stdout, "vendor %d is not allowed for purposes %s\n", *vendorId, formatIDs(purposeIds))
+		return errNotAllowed
+	}
+	_, err = fmt.Fprintf(stdout, "vendor %d is allowed for purposes %s\n", *vendorId, formatIDs(purposeIds))
+	return err
+}
+
+func parseIDs(s string) ([]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var ids []int
+	for _, p := range strings.Split(s, ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid id %q", p)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
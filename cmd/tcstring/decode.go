@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SirDataFR/iabtcfv2"
+)
+
+func runDecode(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("decode", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the segments as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tcString, err := readTCString(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	data, err := iabtcfv2.Decode(tcString)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		b, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(stdout, "%s\n", b)
+		return err
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	segments := []struct {
+		name    string
+		segment interface{}
+	}{
+		{"CoreString", data.CoreString},
+		{"DisclosedVendors", data.DisclosedVendors},
+		{"AllowedVendors", data.AllowedVendors},
+		{"PublisherTC", data.PublisherTC},
+	}
+	for _, s := range segments {
+		v := reflect.ValueOf(s.segment)
+		if v.IsNil() {
+			continue
+		}
+		writeFields(w, s.name, v.Elem())
+	}
+	return w.Flush()
+}
+
+// Writes a row for each field of a segment, except the vendor sets which duplicate the vendor fields
+func writeFields(w io.Writer, segment string, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Type == reflect.TypeOf(&iabtcfv2.IDSet{}) {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", segment, t.Field(i).Name, formatValue(v.Field(i).Interface()))
+	}
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case map[int]bool:
+		var ids []int
+		for id, ok := range v {
+			if ok {
+				ids = append(ids, id)
+			}
+		}
+		sort.Ints(ids)
+		return formatIDs(ids)
+	case []*iabtcfv2.RangeEntry:
+		return formatRangeEntries(v)
+	case []*iabtcfv2.PubRestriction:
+		var s []string
+		for _, r := range v {
+			s = append(s, fmt.Sprintf("purpose %d %s: %s", r.PurposeId, r.RestrictionType, formatRangeEntries(r.RangeEntries)))
+		}
+		return strings.Join(s, "; ")
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func formatIDs(ids []int) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.Itoa(id)
+	}
+	return strings.Join(s, ",")
+}
+
+func formatRangeEntries(entries []*iabtcfv2.RangeEntry) string {
+	s := make([]string, len(entries))
+	for i, entry := range entries {
+		if entry.EndVendorID > entry.StartVendorID {
+			s[i] = fmt.Sprintf("%d-%d", entry.StartVendorID, entry.EndVendorID)
+		} else {
+			s[i] = strconv.Itoa(entry.StartVendorID)
+		}
+	}
+	return strings.Join(s, ",")
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/SirDataFR/iabtcfv2"
+	"gopkg.in/yaml.v3"
+)
+
+func runEncode(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("encode", flag.ContinueOnError)
+	format := fs.String("format", "", "format of the input, json or yaml (default: from the file extension, or json)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("expected a single file, got %d arguments", fs.NArg())
+	}
+
+	r := stdin
+	if fs.NArg() == 1 && fs.Arg(0) != "-" {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+
+		if ext := filepath.Ext(fs.Arg(0)); *format == "" && (ext == ".yaml" || ext == ".yml") {
+			*format = "yaml"
+		}
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "", "json":
+	case "yaml":
+		if b, err = yamlToJSON(b); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	var data iabtcfv2.TCData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	if data.CoreString == nil {
+		return iabtcfv2.ErrMissingCore
+	}
+
+	_, err = fmt.Fprintln(stdout, data.ToTCString())
+	return err
+}
+
+// Converts a YAML document to JSON, so that it is read with the JSON schema of the segments
+func yamlToJSON(b []byte) ([]byte, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,74 @@
+// Command tcstring decodes, inspects and encodes TC Strings
+//
+// Usage:
+//
+//	tcstring decode [-json] [tcstring]
+//	tcstring encode [-format json|yaml] [file]
+//	tcstring check -vendor id -purposes ids [-li] [-flexible] [tcstring]
+//	tcstring version [tcstring]
+//
+// The TC String or file is read from stdin when it is omitted or "-"
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const usage = `usage:
+  tcstring decode [-json] [tcstring]
+  tcstring encode [-format json|yaml] [file]
+  tcstring check -vendor id -purposes ids [-li] [-flexible] [tcstring]
+  tcstring version [tcstring]
+`
+
+// Returned by check when the vendor isn't allowed, to exit with status 1 without printing an error
+var errNotAllowed = errors.New("not allowed")
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout)
+	if errors.Is(err, errNotAllowed) {
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tcstring: %s\n", err)
+		os.Exit(2)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	switch args[0] {
+	case "decode":
+		return runDecode(args[1:], stdin, stdout)
+	case "encode":
+		return runEncode(args[1:], stdin, stdout)
+	case "check":
+		return runCheck(args[1:], stdin, stdout)
+	case "version":
+		return runVersion(args[1:], stdin, stdout)
+	}
+	return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+}
+
+// Returns the TC String given as argument, or read from stdin
+func readTCString(args []string, stdin io.Reader) (string, error) {
+	if len(args) > 1 {
+		return "", fmt.Errorf("expected a single TC String, got %d arguments", len(args))
+	}
+	if len(args) == 1 && args[0] != "-" {
+		return strings.TrimSpace(args[0]), nil
+	}
+
+	b, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const tcString = "CPTZZYAPTZZYABcABCENAgCMAPzAAEPAAAqIDaQBQAMgAgABqAR0A2gDaQAwAMgAgANoAAA.IDaQBQAMgAgABqAR0A2g.QDaQAgAMwAgADUA.eEAAAAAAAUA"
+
+func runString(args []string, stdin string) (string, error) {
+	var out bytes.Buffer
+	err := run(args, strings.NewReader(stdin), &out)
+	return out.String(), err
+}
+
+func TestDecode(t *testing.T) {
+	out, err := runString([]string{"decode", tcString}, "")
+	if err != nil {
+		t.Errorf("TC String should be decoded without error: %s", err)
+	}
+	for _, row := range []string{"CoreString        CmpId", "AllowedVendors    RangeEntries                  25,32-53", "PublisherTC       PubPurposesConsent            1,2,7"} {
+		if !strings.Contains(out, row) {
+			t.Errorf("Table should contain %q:\n%s", row, out)
+		}
+	}
+
+	out, err = runString([]string{"decode", "-json"}, tcString+"\n")
+	if err != nil || !strings.Contains(out, `"cmpId": 92`) {
+		t.Errorf("TC String should be decoded from stdin as JSON: %v\n%s", err, out)
+	}
+
+	if _, err := runString([]string{"decode", "AAAA"}, ""); err == nil {
+		t.Errorf("Malformed TC String should return an error")
+	}
+}
+
+func TestEncode(t *testing.T) {
+	js, _ := runString([]string{"decode", "-json", tcString}, "")
+	out, err := runString([]string{"encode"}, js)
+	if err != nil || strings.TrimSpace(out) != tcString {
+		t.Errorf("JSON should be encoded to the decoded TC String: %v\n%s", err, out)
+	}
+
+	yaml := `
+coreString:
+  version: 2
+  created: 2022-01-26T00:00:00Z
+  lastUpdated: 2022-01-26T00:00:00Z
+  cmpId: 92
+  consentLanguage: EN
+  publisherCC: FR
+  purposesConsent: [1, 3]
+  vendorsConsent: [755]
+`
+	path := filepath.Join(t.TempDir(), "tcdata.yaml")
+	os.WriteFile(path, []byte(yaml), 0644)
+	out, err = runString([]string{"encode", path}, "")
+	if err != nil {
+		t.Errorf("YAML should be encoded without error: %s", err)
+		return
+	}
+	check, err := runString([]string{"check", "-vendor", "755", "-purposes", "1,3", strings.TrimSpace(out)}, "")
+	if err != nil || !strings.Contains(check, "is allowed") {
+		t.Errorf("Encoded TC String should allow vendor 755: %v %s", err, check)
+	}
+
+	if _, err := runString([]string{"encode", "-format", "xml"}, "{}"); err == nil {
+		t.Errorf("Unknown format should return an error")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	out, err := runString([]string{"check", "-vendor", "25", "-purposes", "1,3", tcString}, "")
+	if err != nil || out != "vendor 25 is allowed for purposes 1,3\n" {
+		t.Errorf("Vendor 25 should be allowed: %v %s", err, out)
+	}
+
+	out, err = runString([]string{"check", "-vendor", "25", "-purposes", "1", "-li", tcString}, "")
+	if !errors.Is(err, errNotAllowed) || out != "vendor 25 is not allowed for purposes 1\n" {
+		t.Errorf("Vendor 25 should not be allowed for purpose 1 LI: %v %s", err, out)
+	}
+
+	if _, err := runString([]string{"check", "-purposes", "1", tcString}, ""); err == nil {
+		t.Errorf("Missing vendor should return an error")
+	}
+	if _, err := runString([]string{"check", "-vendor", "25", tcString}, ""); err == nil {
+		t.Errorf("Missing purposes should return an error")
+	}
+	if _, err := runString([]string{"check", "-vendor", "25", "-purposes", "1,a", tcString}, ""); err == nil {
+		t.Errorf("Invalid purpose id should return an error")
+	}
+}
+
+func TestVersion(t *testing.T) {
+	out, err := runString([]string{"version", tcString}, "")
+	expected := "version: 2\nsegment 1: core string\nsegment 2: disclosed vendors\nsegment 3: allowed vendors\nsegment 4: publisher TC\n"
+	if err != nil || out != expected {
+		t.Errorf("Unexpected output: %v\n%s", err, out)
+	}
+
+	out, err = runString([]string{"version", "BOEFEAyOEFEAyAHABDENAI4AAAB9vABAASA"}, "")
+	if err != nil || out != "version: 1\n" {
+		t.Errorf("Unexpected output: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/SirDataFR/iabtcfv2"
+)
+
+func runVersion(args []string, stdin io.Reader, stdout io.Writer) error {
+	tcString, err := readTCString(args, stdin)
+	if err != nil {
+		return err
+	}
+
+	version, err := iabtcfv2.GetVersion(tcString)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "version: %d\n", version)
+	if version != iabtcfv2.TcfVersion2 {
+		return nil
+	}
+
+	// The Core String has no segment type, its first bits are the version
+	segments := strings.Split(tcString, ".")
+	fmt.Fprintf(stdout, "segment 1: %s\n", iabtcfv2.SegmentTypeCoreString)
+	for i, segment := range segments[1:] {
+		segmentType, err := iabtcfv2.GetSegmentType(segment)
+		if err != nil {
+			fmt.Fprintf(stdout, "segment %d: %s\n", i+2, err)
+			continue
+		}
+		fmt.Fprintf(stdout, "segment %d: %s\n", i+2, segmentType)
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+package iabtcfv2
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// CMPAPIData is the TCData object returned by the CMP API __tcfapi('getTCData')
+// It marshals to and from the JSON object of the CMP API
+type CMPAPIData struct {
+	TCString             string          `json:"tcString"`
+	TcfPolicyVersion     int             `json:"tcfPolicyVersion"`
+	CmpId                int             `json:"cmpId"`
+	CmpVersion           int             `json:"cmpVersion"`
+	GdprApplies          bool            `json:"gdprApplies"`
+	EventStatus          string          `json:"eventStatus,omitempty"`
+	CmpStatus            string          `json:"cmpStatus,omitempty"`
+	ListenerId           *int            `json:"listenerId,omitempty"`
+	IsServiceSpecific    bool            `json:"isServiceSpecific"`
+	UseNonStandardTexts  bool            `json:"useNonStandardTexts"`
+	PublisherCC          string          `json:"publisherCC"`
+	PurposeOneTreatment  bool            `json:"purposeOneTreatment"`
+	OutOfBand            CMPAPIOutOfBand `json:"outOfBand"`
+	Purpose              CMPAPIConsents  `json:"purpose"`
+	Vendor               CMPAPIConsents  `json:"vendor"`
+	SpecialFeatureOptIns CMPAPIVector    `json:"specialFeatureOptins"`
+	Publisher            CMPAPIPublisher `json:"publisher"`
+}
+
+type CMPAPIOutOfBand struct {
+	AllowedVendors   CMPAPIVector `json:"allowedVendors"`
+	DisclosedVendors CMPAPIVector `json:"disclosedVendors"`
+}
+
+type CMPAPIConsents struct {
+	Consents            CMPAPIVector `json:"consents"`
+	LegitimateInterests CMPAPIVector `json:"legitimateInterests"`
+}
+
+type CMPAPIPublisher struct {
+	Consents            CMPAPIVector   `json:"consents"`
+	LegitimateInterests CMPAPIVector   `json:"legitimateInterests"`
+	CustomPurpose       CMPAPIConsents `json:"customPurpose"`
+	// Restriction type of each vendor id, by purpose id
+	Restrictions map[int]map[int]int `json:"restrictions"`
+}
+
+// CMPAPIVector is a JSON object of booleans keyed by id, marshaled in ascending id order
+type CMPAPIVector map[int]bool
+
+// Returns the ids set to true in ascending order
+func (v CMPAPIVector) IDs() []int {
+	var ids []int
+	for id, ok := range v {
+		if ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Returns the highest id listed, whether it is set to true or false
+func (v CMPAPIVector) maxId() int {
+	max := 0
+	for id := range v {
+		if id > max {
+			max = id
+		}
+	}
+	return max
+}
+
+func (v CMPAPIVector) bitField() map[int]bool {
+	m := map[int]bool{}
+	for _, id := range v.IDs() {
+		m[id] = true
+	}
+	return m
+}
+
+func (v CMPAPIVector) MarshalJSON() ([]byte, error) {
+	ids := make([]int, 0, len(v))
+	for id := range v {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, id := range ids {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('"')
+		b.WriteString(strconv.Itoa(id))
+		b.WriteString(`":`)
+		b.WriteString(strconv.FormatBool(v[id]))
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+type CMPAPIOptions struct {
+	// Restricts vendors to these ids like the vendorIds argument of getTCData, all vendors are returned when nil
+	VendorIds   []int
+	GdprApplies bool
+	EventStatus string
+	CmpStatus   string
+	ListenerId  *int
+}
+
+// Returns the TCData object of the CMP API for this TC String
+// Purposes, special features and vendors are listed from id 1 to the highest id set, or for each of opts.VendorIds
+// Custom purposes are listed from id 1 to NumCustomPurposes
+// Publisher restrictions are listed for each vendor id of their ranges
+func (t *TCData) ToCMPAPIData(opts CMPAPIOptions) *CMPAPIData {
+	c := t.CoreString
+	d := &CMPAPIData{
+		TCString:             t.ToTCString(),
+		TcfPolicyVersion:     c.TcfPolicyVersion,
+		CmpId:                c.CmpId,
+		CmpVersion:           c.CmpVersion,
+		GdprApplies:          opts.GdprApplies,
+		EventStatus:          opts.EventStatus,
+		CmpStatus:            opts.CmpStatus,
+		ListenerId:           opts.ListenerId,
+		IsServiceSpecific:    c.IsServiceSpecific,
+		UseNonStandardTexts:  c.UseNonStandardTexts,
+		PublisherCC:          c.PublisherCC,
+		PurposeOneTreatment:  c.PurposeOneTreatment,
+		SpecialFeatureOptIns: newCMPAPIVector(c.IsSpecialFeatureAllowed, bitsSpecialFeatureOptIns, nil),
+		Purpose: CMPAPIConsents{
+			Consents:            newCMPAPIVector(c.IsPurposeAllowed, bitsPurposesConsent, nil),
+			LegitimateInterests: newCMPAPIVector(c.IsPurposeLIAllowed, bitsPurposesLITransparency, nil),
+		},
+		Vendor: CMPAPIConsents{
+			Consents:            newCMPAPIVector(c.IsVendorAllowed, c.MaxVendorId, opts.VendorIds),
+			LegitimateInterests: newCMPAPIVector(c.IsVendorLIAllowed, c.MaxVendorIdLI, opts.VendorIds),
+		},
+		OutOfBand: CMPAPIOutOfBand{
+			AllowedVendors:   CMPAPIVector{},
+			DisclosedVendors: CMPAPIVector{},
+		},
+		Publisher: CMPAPIPublisher{
+			Consents:            CMPAPIVector{},
+			LegitimateInterests: CMPAPIVector{},
+			CustomPurpose: CMPAPIConsents{
+				Consents:            CMPAPIVector{},
+				LegitimateInterests: CMPAPIVector{},
+			},
+			Restrictions: map[int]map[int]int{},
+		},
+	}
+
+	if t.AllowedVendors != nil {
+		d.OutOfBand.AllowedVendors = newCMPAPIVector(t.AllowedVendors.IsVendorAllowed, t.AllowedVendors.MaxVendorId, opts.VendorIds)
+	}
+	if t.DisclosedVendors != nil {
+		d.OutOfBand.DisclosedVendors = newCMPAPIVector(t.DisclosedVendors.IsVendorDisclosed, t.DisclosedVendors.MaxVendorId, opts.VendorIds)
+	}
+
+	if p := t.PublisherTC; p != nil {
+		d.Publisher.Consents = newCMPAPIVector(p.IsPurposeAllowed, bitsPubPurposesConsent, nil)
+		d.Publisher.LegitimateInterests = newCMPAPIVector(p.IsPurposeLIAllowed, bitsPubPurposesLITransparency, nil)
+		d.Publisher.CustomPurpose.Consents = newFullCMPAPIVector(p.IsCustomPurposeAllowed, p.NumCustomPurposes)
+		d.Publisher.CustomPurpose.LegitimateInterests = newFullCMPAPIVector(p.IsCustomPurposeLIAllowed, p.NumCustomPurposes)
+	}
+
+	var filter *IDSet
+	if opts.VendorIds != nil {
+		filter = NewIDSet(opts.VendorIds...)
+	}
+	for _, r := range c.PubRestrictions {
+		for _, entry := range r.RangeEntries {
+			for id := entry.StartVendorID; id <= entry.EndVendorID; id++ {
+				if filter != nil && !filter.Contains(id) {
+					continue
+				}
+				vendors, ok := d.Publisher.Restrictions[r.PurposeId]
+				if !ok {
+					vendors = map[int]int{}
+					d.Publisher.Restrictions[r.PurposeId] = vendors
+				}
+				vendors[id] = int(r.RestrictionType)
+			}
+		}
+	}
+
+	return d
+}
+
+// Lists ids from 1 to the highest id allowed by f within max, or each of ids if not nil
+func newCMPAPIVector(f func(int) bool, max int, ids []int) CMPAPIVector {
+	v := CMPAPIVector{}
+	if ids != nil {
+		for _, id := range ids {
+			v[id] = f(id)
+		}
+		return v
+	}
+
+	last := 0
+	for id := 1; id <= max; id++ {
+		if f(id) {
+			last = id
+		}
+	}
+	for id := 1; id <= last; id++ {
+		v[id] = f(id)
+	}
+	return v
+}
+
+// Lists ids from 1 to max
+func newFullCMPAPIVector(f func(int) bool, max int) CMPAPIVector {
+	v := CMPAPIVector{}
+	for id := 1; id <= max; id++ {
+		v[id] = f(id)
+	}
+	return v
+}
+
+// Fields of a TC String that the CMP API doesn't expose
+type CMPAPIConversionOptions struct {
+	// Defaults to the current time when zero
+	Created time.Time
+	// Defaults to Created when zero
+	LastUpdated   time.Time
+	ConsentScreen int
+	// Defaults to "EN" when empty
+	ConsentLanguage   string
+	VendorListVersion int
+}
+
+// Builds a TCData from the fields of the CMP API object, or returns an error wrapping ErrInvalidValue
+// if a value can't be encoded in a TC String, see TCDataBuilder.Build
+// Fields the CMP API doesn't expose are taken from TCString if it can be decoded, or else from opts
+// Vendors and publisher restrictions are set with the shortest encoding, and the segments are only
+// added if they have at least one id set, NumCustomPurposes being the highest custom purpose id listed
+// The TCData is encoded and decoded again, so that it is the same as if it was read from a TC String
+func (d *CMPAPIData) ToTCData(opts CMPAPIConversionOptions) (*TCData, error) {
+	if d.TCString != "" {
+		if t, err := Decode(d.TCString); err == nil {
+			c := t.CoreString
+			opts.Created = c.Created
+			opts.LastUpdated = c.LastUpdated
+			opts.ConsentScreen = c.ConsentScreen
+			opts.ConsentLanguage = c.ConsentLanguage
+			opts.VendorListVersion = c.VendorListVersion
+		}
+	}
+	if opts.Created.IsZero() {
+		opts.Created = time.Now()
+	}
+	if opts.LastUpdated.IsZero() {
+		opts.LastUpdated = opts.Created
+	}
+	if opts.ConsentLanguage == "" {
+		opts.ConsentLanguage = "EN"
+	}
+
+	b := NewTCDataBuilder().
+		WithCreated(opts.Created).
+		WithLastUpdated(opts.LastUpdated).
+		WithCMP(d.CmpId, d.CmpVersion).
+		WithConsentScreen(opts.ConsentScreen).
+		WithConsentLanguage(opts.ConsentLanguage).
+		WithGVLVersion(opts.VendorListVersion).
+		WithPolicyVersion(d.TcfPolicyVersion).
+		WithPublisherCC(d.PublisherCC).
+		ServiceSpecific(d.IsServiceSpecific).
+		NonStandardTexts(d.UseNonStandardTexts).
+		PurposeOneTreatment(d.PurposeOneTreatment).
+		OptInSpecialFeatures(d.SpecialFeatureOptIns.IDs()...).
+		ConsentPurposes(d.Purpose.Consents.IDs()...).
+		LIPurposes(d.Purpose.LegitimateInterests.IDs()...).
+		ConsentVendors(d.Vendor.Consents.IDs()...).
+		LIVendors(d.Vendor.LegitimateInterests.IDs()...)
+
+	for purposeId, vendors := range d.Publisher.Restrictions {
+		for vendorId, restrictionType := range vendors {
+			b.AddPubRestriction(purposeId, RestrictionType(restrictionType), vendorId)
+		}
+	}
+
+	if ids := d.OutOfBand.DisclosedVendors.IDs(); len(ids) > 0 {
+		b.DiscloseVendors(ids...)
+	}
+	if ids := d.OutOfBand.AllowedVendors.IDs(); len(ids) > 0 {
+		b.AllowVendors(ids...)
+	}
+
+	p := d.Publisher
+	numCustomPurposes := p.CustomPurpose.Consents.maxId()
+	if max := p.CustomPurpose.LegitimateInterests.maxId(); max > numCustomPurposes {
+		numCustomPurposes = max
+	}
+	if len(p.Consents.IDs()) > 0 || len(p.LegitimateInterests.IDs()) > 0 || numCustomPurposes > 0 {
+		b.WithPublisherTC(&PublisherTC{
+			PubPurposesConsent:           p.Consents.bitField(),
+			PubPurposesLITransparency:    p.LegitimateInterests.bitField(),
+			NumCustomPurposes:            numCustomPurposes,
+			CustomPurposesConsent:        p.CustomPurpose.Consents.bitField(),
+			CustomPurposesLITransparency: p.CustomPurpose.LegitimateInterests.bitField(),
+		})
+	}
+
+	t, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return Decode(t.ToTCString())
+}
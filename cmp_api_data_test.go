@@ -0,0 +1,145 @@
+package iabtcfv2
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+const cmpAPIJSON = `{
+	"tcString": "",
+	"tcfPolicyVersion": 4,
+	"cmpId": 92,
+	"cmpVersion": 3,
+	"gdprApplies": true,
+	"isServiceSpecific": true,
+	"useNonStandardTexts": false,
+	"publisherCC": "FR",
+	"purposeOneTreatment": false,
+	"outOfBand": {"allowedVendors": {}, "disclosedVendors": {"2": true, "8": true}},
+	"purpose": {"consents": {"1": true, "2": false, "3": true}, "legitimateInterests": {"2": true}},
+	"vendor": {"consents": {"2": true, "8": true, "755": true}, "legitimateInterests": {"8": true, "9": false}},
+	"specialFeatureOptins": {"1": true},
+	"publisher": {
+		"consents": {"1": true},
+		"legitimateInterests": {},
+		"customPurpose": {"consents": {"2": true}, "legitimateInterests": {"1": true}},
+		"restrictions": {"2": {"8": 1, "9": 1, "10": 1, "755": 0}}
+	}
+}`
+
+func TestCMPAPIData(t *testing.T) {
+	var d CMPAPIData
+	if err := json.Unmarshal([]byte(cmpAPIJSON), &d); err != nil {
+		t.Errorf("CMP API object should be unmarshaled without error: %s", err)
+		return
+	}
+
+	data, err := d.ToTCData(CMPAPIConversionOptions{Created: timeFromDeciSeconds(16431552000), VendorListVersion: 150})
+	if err != nil {
+		t.Errorf("CMP API object should be converted without error: %s", err)
+		return
+	}
+
+	c := data.CoreString
+	if c.CmpId != 92 || c.CmpVersion != 3 || c.TcfPolicyVersion != 4 || c.VendorListVersion != 150 ||
+		c.ConsentLanguage != "EN" || c.PublisherCC != "FR" || !c.IsServiceSpecific {
+		t.Errorf("Unexpected core string: %+v", c)
+	}
+	if !c.IsPurposeAllowed(3) || c.IsPurposeAllowed(2) || !c.IsPurposeLIAllowed(2) || !c.IsSpecialFeatureAllowed(1) {
+		t.Errorf("Purposes should be converted")
+	}
+	if !c.IsVendorAllowed(755) || c.IsVendorAllowed(9) || !c.IsVendorLIAllowed(8) || c.IsVendorLIAllowed(9) {
+		t.Errorf("Vendors should be converted")
+	}
+	if c.NumPubRestrictions != 2 || c.PubRestrictions[0].RestrictionType != RestrictionTypeNotAllowed ||
+		c.PubRestrictions[1].NumEntries != 1 || !c.PubRestrictions[1].IsVendorIncluded(9) {
+		t.Errorf("Publisher restrictions should be grouped by restriction type: %+v", c.PubRestrictions)
+	}
+	if data.AllowedVendors != nil || data.DisclosedVendors == nil || !data.DisclosedVendors.IsVendorDisclosed(8) {
+		t.Errorf("Only segments with vendors should be added")
+	}
+	if data.PublisherTC == nil || data.PublisherTC.NumCustomPurposes != 2 || !data.PublisherTC.IsCustomPurposeLIAllowed(1) {
+		t.Errorf("Publisher TC should be converted: %+v", data.PublisherTC)
+	}
+
+	r := data.ToCMPAPIData(CMPAPIOptions{GdprApplies: true})
+	if r.TCString != data.ToTCString() || r.CmpId != 92 || !r.GdprApplies {
+		t.Errorf("Unexpected CMP API object: %+v", r)
+	}
+	if len(r.Vendor.Consents) != 755 || !r.Vendor.Consents[755] || r.Vendor.Consents[9] {
+		t.Errorf("Vendors should be listed up to the highest vendor id: %d", len(r.Vendor.Consents))
+	}
+	if len(r.Purpose.Consents) != 3 || r.Purpose.Consents[2] {
+		t.Errorf("Purposes should be listed up to the highest purpose id: %v", r.Purpose.Consents)
+	}
+	if r.Publisher.Restrictions[2][10] != 1 || r.Publisher.Restrictions[2][755] != 0 || len(r.Publisher.Restrictions[2]) != 4 {
+		t.Errorf("Publisher restrictions should be listed by vendor id: %v", r.Publisher.Restrictions)
+	}
+
+	// The TC String keeps the fields the CMP API doesn't expose
+	again, err := r.ToTCData(CMPAPIConversionOptions{})
+	if err != nil || again.ToTCString() != data.ToTCString() {
+		t.Errorf("CMP API object should be converted back to the same TC String: %v", err)
+	}
+
+	// Custom purposes are listed up to NumCustomPurposes even if the last ones aren't set
+	data.PublisherTC.NumCustomPurposes = 3
+	r = data.ToCMPAPIData(CMPAPIOptions{})
+	if len(r.Publisher.CustomPurpose.Consents) != 3 || r.Publisher.CustomPurpose.Consents[3] {
+		t.Errorf("Custom purposes should be listed up to NumCustomPurposes: %v", r.Publisher.CustomPurpose.Consents)
+	}
+	again, err = r.ToTCData(CMPAPIConversionOptions{})
+	if err != nil || again.PublisherTC == nil || again.PublisherTC.NumCustomPurposes != 3 {
+		t.Errorf("NumCustomPurposes should be kept when converted back: %v %+v", err, again.PublisherTC)
+	}
+}
+
+func TestCMPAPIDataErrors(t *testing.T) {
+	changes := map[string]func(d *CMPAPIData){
+		"cmp id":          func(d *CMPAPIData) { d.CmpId = 5000 },
+		"purpose":         func(d *CMPAPIData) { d.Purpose.Consents[30] = true },
+		"special feature": func(d *CMPAPIData) { d.SpecialFeatureOptIns[13] = true },
+		"vendor":          func(d *CMPAPIData) { d.Vendor.Consents[70000] = true },
+		"custom purpose":  func(d *CMPAPIData) { d.Publisher.CustomPurpose.Consents[70] = true },
+		"restriction":     func(d *CMPAPIData) { d.Publisher.Restrictions[2][11] = 5 },
+	}
+
+	for name, change := range changes {
+		var d CMPAPIData
+		json.Unmarshal([]byte(cmpAPIJSON), &d)
+		change(&d)
+		if _, err := d.ToTCData(CMPAPIConversionOptions{}); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("Invalid %s should return ErrInvalidValue: %v", name, err)
+		}
+	}
+}
+
+func TestCMPAPIDataVendorIds(t *testing.T) {
+	var d CMPAPIData
+	json.Unmarshal([]byte(cmpAPIJSON), &d)
+	data, _ := d.ToTCData(CMPAPIConversionOptions{Created: timeFromDeciSeconds(16431552000)})
+
+	r := data.ToCMPAPIData(CMPAPIOptions{VendorIds: []int{8, 9, 1000}})
+	if len(r.Vendor.Consents) != 3 || !r.Vendor.Consents[8] || r.Vendor.Consents[9] || r.Vendor.Consents[1000] {
+		t.Errorf("Only requested vendors should be listed: %v", r.Vendor.Consents)
+	}
+	if len(r.OutOfBand.DisclosedVendors) != 3 || len(r.Publisher.Restrictions[2]) != 2 {
+		t.Errorf("Only requested vendors should be listed: %v %v", r.OutOfBand.DisclosedVendors, r.Publisher.Restrictions)
+	}
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Errorf("CMP API object should be marshaled without error: %s", err)
+	}
+	var m map[string]interface{}
+	json.Unmarshal(b, &m)
+	if m["specialFeatureOptins"].(map[string]interface{})["1"] != true || m["gdprApplies"] != false {
+		t.Errorf("Unexpected JSON: %s", b)
+	}
+
+	v, _ := json.Marshal(CMPAPIVector{10: true, 2: false, 1: true})
+	if string(v) != `{"1":true,"2":false,"10":true}` {
+		t.Errorf("Vector should be marshaled in id order: %s", v)
+	}
+}
@@ -6,6 +6,7 @@ const (
 	SegmentTypeUndefined        SegmentType = -1
 	SegmentTypeCoreString       SegmentType = 0
 	SegmentTypeDisclosedVendors SegmentType = 1
+	SegmentTypeAllowedVendors   SegmentType = 2
 	SegmentTypePublisherTC      SegmentType = 3
 )
 
@@ -26,6 +27,14 @@ const (
 	RestrictionTypeUndefined      RestrictionType = 3
 )
 
+type LegalBasis int
+
+const (
+	LegalBasisNone               LegalBasis = 0
+	LegalBasisConsent            LegalBasis = 1
+	LegalBasisLegitimateInterest LegalBasis = 2
+)
+
 const (
 	bitsBool = 1
 	bitsChar = 6
@@ -62,4 +71,8 @@ const (
 	bitsPubPurposesConsent        = 24
 	bitsPubPurposesLITransparency = 24
 	bitsNumCustomPurposes         = 6
+
+	bitsV1PurposesAllowed = 24
+	bitsV1EncodingType    = bitsBool
+	bitsV1DefaultConsent  = bitsBool
 )
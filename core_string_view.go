@@ -0,0 +1,485 @@
+package iabtcfv2
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Bit offsets of the fixed size fields of a Core String
+const (
+	offsetVersion                = 0
+	offsetCreated                = offsetVersion + bitsVersion
+	offsetLastUpdated            = offsetCreated + bitsCreated
+	offsetCmpId                  = offsetLastUpdated + bitsLastUpdated
+	offsetCmpVersion             = offsetCmpId + bitsCmpId
+	offsetConsentScreen          = offsetCmpVersion + bitsCmpVersion
+	offsetConsentLanguage        = offsetConsentScreen + bitsConsentScreen
+	offsetVendorListVersion      = offsetConsentLanguage + bitsConsentLanguage
+	offsetTcfPolicyVersion       = offsetVendorListVersion + bitsVendorListVersion
+	offsetIsServiceSpecific      = offsetTcfPolicyVersion + bitsTcfPolicyVersion
+	offsetUseNonStandardTexts    = offsetIsServiceSpecific + bitsIsServiceSpecific
+	offsetSpecialFeatureOptIns   = offsetUseNonStandardTexts + bitsUseNonStandardTexts
+	offsetPurposesConsent        = offsetSpecialFeatureOptIns + bitsSpecialFeatureOptIns
+	offsetPurposesLITransparency = offsetPurposesConsent + bitsPurposesConsent
+	offsetPurposeOneTreatment    = offsetPurposesLITransparency + bitsPurposesLITransparency
+	offsetPublisherCC            = offsetPurposeOneTreatment + bitsPurposeOneTreatment
+	offsetMaxVendorId            = offsetPublisherCC + bitsPublisherCC
+)
+
+// CoreStringView answers the checks of a CoreString by reading bits of the decoded Core String
+// at offsets computed once, without materializing maps or range entries
+// The zero value is an empty view, and Reset can be called again to reuse its buffer without allocating
+type CoreStringView struct {
+	// Overrides the rules of TcfPolicyVersion when not nil
+	PolicyRules *PolicyRules
+
+	src          []byte
+	bytes        []byte
+	vendors      viewVendors
+	vendorsLI    viewVendors
+	restrictions []viewRestriction
+}
+
+// viewVendors locates a vendor section, either a bit field or range entries starting at offset
+type viewVendors struct {
+	maxVendorId     int
+	isRangeEncoding bool
+	numEntries      int
+	offset          uint
+}
+
+// viewRestriction locates the range entries of a publisher restriction starting at offset
+type viewRestriction struct {
+	purposeId       int
+	restrictionType RestrictionType
+	numEntries      int
+	offset          uint
+}
+
+// viewRestrictions are the restriction types applying to a vendor for a purpose
+// restricted is true if the purpose has restrictions, whether they apply to the vendor or not
+type viewRestrictions struct {
+	restricted     bool
+	notAllowed     bool
+	requireConsent bool
+	requireLI      bool
+}
+
+// Decodes a Core String and returns a view of it
+// tcString can be a full TC String, in which case only its first segment is read
+func NewCoreStringView(tcString string) (*CoreStringView, error) {
+	v := &CoreStringView{}
+	if err := v.Reset(tcString); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Decodes a Core String into the view, reusing its buffer
+// tcString can be a full TC String, in which case only its first segment is read
+func (v *CoreStringView) Reset(tcString string) error {
+	if i := strings.IndexByte(tcString, '.'); i >= 0 {
+		tcString = tcString[:i]
+	}
+
+	// Copying into src avoids the allocation of a []byte conversion
+	v.src = append(v.src[:0], tcString...)
+	n := base64.RawURLEncoding.DecodedLen(len(v.src))
+	if cap(v.bytes) < n {
+		v.bytes = make([]byte, n)
+	}
+	n, err := base64.RawURLEncoding.Decode(v.bytes[:n], v.src)
+	if err != nil {
+		v.clear()
+		return &DecodeError{SegmentType: SegmentTypeCoreString, Err: fmt.Errorf("%w: %v", ErrBadBase64, err)}
+	}
+	v.bytes = v.bytes[:n]
+
+	if err = v.locate(); err != nil {
+		v.clear()
+		return err
+	}
+	return nil
+}
+
+// Empties the view, keeping its buffers and policy rules
+func (v *CoreStringView) clear() {
+	*v = CoreStringView{PolicyRules: v.PolicyRules, src: v.src[:0], bytes: v.bytes[:0], restrictions: v.restrictions[:0]}
+}
+
+// Computes the offsets of the variable size sections
+func (v *CoreStringView) locate() (err error) {
+	offset := uint(offsetMaxVendorId)
+	if offset, err = v.locateVendors(&v.vendors, offset, "VendorsConsent"); err != nil {
+		return err
+	}
+	if offset, err = v.locateVendors(&v.vendorsLI, offset, "VendorsLITransparency"); err != nil {
+		return err
+	}
+	if err = v.require("PubRestrictions", offset, bitsNumPubRestrictions); err != nil {
+		return err
+	}
+	n := v.readInt(offset, bitsNumPubRestrictions)
+	offset += bitsNumPubRestrictions
+	v.restrictions = v.restrictions[:0]
+	for i := 0; i < n; i++ {
+		if err = v.require("PubRestrictions", offset, bitsPubRestrictionsEntryPurposeId+bitsPubRestrictionsEntryRestrictionType+bitsNumEntries); err != nil {
+			return err
+		}
+		r := viewRestriction{}
+		r.purposeId = v.readInt(offset, bitsPubRestrictionsEntryPurposeId)
+		offset += bitsPubRestrictionsEntryPurposeId
+		r.restrictionType = RestrictionType(v.readInt(offset, bitsPubRestrictionsEntryRestrictionType))
+		offset += bitsPubRestrictionsEntryRestrictionType
+		r.numEntries = v.readInt(offset, bitsNumEntries)
+		r.offset = offset + bitsNumEntries
+		if offset, err = v.skipRangeEntries(offset, "PubRestrictions"); err != nil {
+			return err
+		}
+		v.restrictions = append(v.restrictions, r)
+	}
+	return nil
+}
+
+func (v *CoreStringView) locateVendors(vendors *viewVendors, offset uint, field string) (uint, error) {
+	if err := v.require(field, offset, bitsMaxVendorId+bitsIsRangeEncoding); err != nil {
+		return 0, err
+	}
+	vendors.maxVendorId = v.readInt(offset, bitsMaxVendorId)
+	vendors.isRangeEncoding = v.readBool(offset + bitsMaxVendorId)
+	offset += bitsMaxVendorId + bitsIsRangeEncoding
+
+	if !vendors.isRangeEncoding {
+		vendors.numEntries = 0
+		vendors.offset = offset
+		if err := v.require(field, offset, uint(vendors.maxVendorId)); err != nil {
+			return 0, err
+		}
+		return offset + uint(vendors.maxVendorId), nil
+	}
+
+	if err := v.require(field, offset, bitsNumEntries); err != nil {
+		return 0, err
+	}
+	vendors.numEntries = v.readInt(offset, bitsNumEntries)
+	vendors.offset = offset + bitsNumEntries
+	return v.skipRangeEntries(offset, field)
+}
+
+// Returns the offset following the range entries starting at offset
+func (v *CoreStringView) skipRangeEntries(offset uint, field string) (uint, error) {
+	if err := v.require(field, offset, bitsNumEntries); err != nil {
+		return 0, err
+	}
+	n := v.readInt(offset, bitsNumEntries)
+	offset += bitsNumEntries
+	for i := 0; i < n; i++ {
+		if err := v.require(field, offset, bitsIsRangeEncoding); err != nil {
+			return 0, err
+		}
+		size := uint(bitsIsRangeEncoding + bitsVendorId)
+		if v.readBool(offset) {
+			size += bitsVendorId
+		}
+		if err := v.require(field, offset, size); err != nil {
+			return 0, err
+		}
+		offset += size
+	}
+	return offset, nil
+}
+
+func (v *CoreStringView) require(field string, offset uint, n uint) error {
+	available := uint(len(v.bytes)) * 8
+	if offset+n <= available {
+		return nil
+	}
+
+	if offset > available {
+		available = offset
+	}
+	return &DecodeError{
+		SegmentType: SegmentTypeCoreString,
+		Field:       field,
+		Offset:      offset,
+		Expected:    n,
+		Available:   available - offset,
+		Err:         ErrTruncated,
+	}
+}
+
+func (v *CoreStringView) readBool(offset uint) bool {
+	b := Bits{Bytes: v.bytes, Position: offset}
+	return b.ReadBool()
+}
+
+// Reads n bits at offset a byte at a time, since the view reads the same fields repeatedly
+func (v *CoreStringView) readInt(offset uint, n uint) int {
+	value := 0
+	for n > 0 {
+		available := 8 - offset%8
+		size := available
+		if n < size {
+			size = n
+		}
+		bits := int(v.bytes[offset/8]>>(available-size)) & (1<<size - 1)
+		value = value<<size | bits
+		offset += size
+		n -= size
+	}
+	return value
+}
+
+// Returns the value of bit id of the bit field of n bits starting at offset
+func (v *CoreStringView) readBitField(offset uint, n int, id int) bool {
+	if id < 1 || id > n {
+		return false
+	}
+	return v.readBool(offset + uint(id-1))
+}
+
+// Returns the offset following the range entry at offset, and whether it contains id
+func (v *CoreStringView) readRangeEntry(offset uint, id int) (uint, bool) {
+	isRange := v.readBool(offset)
+	offset += bitsIsRangeEncoding
+	start := v.readInt(offset, bitsVendorId)
+	offset += bitsVendorId
+	end := start
+	if isRange {
+		end = v.readInt(offset, bitsVendorId)
+		offset += bitsVendorId
+	}
+	return offset, start <= id && id <= end
+}
+
+func (v *CoreStringView) containsVendor(vendors *viewVendors, id int) bool {
+	if !vendors.isRangeEncoding {
+		return v.readBitField(vendors.offset, vendors.maxVendorId, id)
+	}
+	return v.containsRange(vendors.offset, vendors.numEntries, id)
+}
+
+// Returns true if one of the n range entries starting at offset contains id
+func (v *CoreStringView) containsRange(offset uint, n int, id int) bool {
+	for i := 0; i < n; i++ {
+		var found bool
+		if offset, found = v.readRangeEntry(offset, id); found {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the restriction types applying to vendor id for purpose id
+func (v *CoreStringView) restrictionsFor(purposeId int, vendorId int) (r viewRestrictions) {
+	for i := range v.restrictions {
+		restriction := &v.restrictions[i]
+		if restriction.purposeId != purposeId {
+			continue
+		}
+		r.restricted = true
+		if !v.containsRange(restriction.offset, restriction.numEntries, vendorId) {
+			continue
+		}
+
+		switch restriction.restrictionType {
+		case RestrictionTypeNotAllowed:
+			r.notAllowed = true
+		case RestrictionTypeRequireConsent:
+			r.requireConsent = true
+		case RestrictionTypeRequireLI:
+			r.requireLI = true
+		}
+	}
+	return r
+}
+
+func (v *CoreStringView) Version() int {
+	return v.readField(offsetVersion, bitsVersion)
+}
+
+func (v *CoreStringView) Created() time.Time {
+	return v.readTime(offsetCreated)
+}
+
+func (v *CoreStringView) LastUpdated() time.Time {
+	return v.readTime(offsetLastUpdated)
+}
+
+func (v *CoreStringView) CmpId() int {
+	return v.readField(offsetCmpId, bitsCmpId)
+}
+
+func (v *CoreStringView) CmpVersion() int {
+	return v.readField(offsetCmpVersion, bitsCmpVersion)
+}
+
+func (v *CoreStringView) VendorListVersion() int {
+	return v.readField(offsetVendorListVersion, bitsVendorListVersion)
+}
+
+func (v *CoreStringView) TcfPolicyVersion() int {
+	return v.readField(offsetTcfPolicyVersion, bitsTcfPolicyVersion)
+}
+
+func (v *CoreStringView) IsServiceSpecific() bool {
+	return v.readField(offsetIsServiceSpecific, bitsIsServiceSpecific) == 1
+}
+
+func (v *CoreStringView) PurposeOneTreatment() bool {
+	return v.readField(offsetPurposeOneTreatment, bitsPurposeOneTreatment) == 1
+}
+
+func (v *CoreStringView) MaxVendorId() int {
+	return v.vendors.maxVendorId
+}
+
+func (v *CoreStringView) MaxVendorIdLI() int {
+	return v.vendorsLI.maxVendorId
+}
+
+// Reads a fixed size field, or returns 0 if the view is empty
+func (v *CoreStringView) readField(offset uint, n uint) int {
+	if len(v.bytes)*8 < offsetMaxVendorId {
+		return 0
+	}
+	return v.readInt(offset, n)
+}
+
+func (v *CoreStringView) readTime(offset uint) time.Time {
+	if len(v.bytes)*8 < offsetMaxVendorId {
+		return time.Time{}
+	}
+	b := TCEncoder{&Bits{Bytes: v.bytes, Position: offset}}
+	return b.ReadTime()
+}
+
+// Returns true if user has given consent to special feature id
+func (v *CoreStringView) IsSpecialFeatureAllowed(id int) bool {
+	return len(v.bytes) > 0 && v.readBitField(offsetSpecialFeatureOptIns, bitsSpecialFeatureOptIns, id)
+}
+
+// Returns true if user has given consent to purpose id
+func (v *CoreStringView) IsPurposeAllowed(id int) bool {
+	return len(v.bytes) > 0 && v.readBitField(offsetPurposesConsent, bitsPurposesConsent, id)
+}
+
+// Returns true if legitimate interest is established for purpose id
+// and user didn't exercise their right to object
+func (v *CoreStringView) IsPurposeLIAllowed(id int) bool {
+	return len(v.bytes) > 0 && v.readBitField(offsetPurposesLITransparency, bitsPurposesLITransparency, id)
+}
+
+// Returns true if user has given consent to vendor id processing their personal data
+func (v *CoreStringView) IsVendorAllowed(id int) bool {
+	return len(v.bytes) > 0 && v.containsVendor(&v.vendors, id)
+}
+
+// Returns true if transparency for vendor id's legitimate interest is established
+// and user didn't exercise their right to object
+func (v *CoreStringView) IsVendorLIAllowed(id int) bool {
+	return len(v.bytes) > 0 && v.containsVendor(&v.vendorsLI, id)
+}
+
+// Returns true if user has given consent to vendor id processing all purposes ids
+// and publisher hasn't set restrictions for them
+func (v *CoreStringView) IsVendorAllowedForPurposes(id int, purposeIds ...int) bool {
+	return v.vendorAllowedForPurposes(id, purposeIds, LegalBasisConsent)
+}
+
+// Returns true if transparency for vendor id's legitimate interest is established for all purpose ids
+// and publisher hasn't set restrictions for them
+func (v *CoreStringView) IsVendorAllowedForPurposesLI(id int, purposeIds ...int) bool {
+	return v.vendorAllowedForPurposes(id, purposeIds, LegalBasisLegitimateInterest)
+}
+
+// Returns true if user has given consent to vendor id processing all purposes ids
+// or if transparency for its legitimate interest is established in accordance with publisher restrictions
+func (v *CoreStringView) IsVendorAllowedForFlexiblePurposes(id int, purposeIds ...int) bool {
+	return v.vendorAllowedForFlexiblePurposes(id, purposeIds, LegalBasisConsent)
+}
+
+// Returns true if transparency for vendor id's legitimate interest is established for all purpose ids
+// or if user has given consent in accordance with publisher restrictions
+func (v *CoreStringView) IsVendorAllowedForFlexiblePurposesLI(id int, purposeIds ...int) bool {
+	return v.vendorAllowedForFlexiblePurposes(id, purposeIds, LegalBasisLegitimateInterest)
+}
+
+// Same evaluation as CoreString.vendorAllowedForPurposes
+func (v *CoreStringView) vendorAllowedForPurposes(id int, purposeIds []int, legalBasis LegalBasis) bool {
+	if !v.hasVendor(id, legalBasis) {
+		return false
+	}
+
+	for _, p := range purposeIds {
+		if !v.hasPurpose(p, legalBasis) {
+			return false
+		}
+	}
+
+	for _, p := range purposeIds {
+		r := v.restrictionsFor(p, id)
+		if r.notAllowed ||
+			(r.requireConsent && legalBasis != LegalBasisConsent) ||
+			(r.requireLI && legalBasis != LegalBasisLegitimateInterest) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Same evaluation as CoreString.vendorAllowedForFlexiblePurposes
+func (v *CoreStringView) vendorAllowedForFlexiblePurposes(id int, purposeIds []int, legalBasis LegalBasis) bool {
+	for _, p := range purposeIds {
+		r := v.restrictionsFor(p, id)
+		if r.notAllowed ||
+			(r.requireConsent && (!v.hasVendor(id, LegalBasisConsent) || !v.hasPurpose(p, LegalBasisConsent))) ||
+			(r.requireLI && (!v.hasVendor(id, LegalBasisLegitimateInterest) || !v.hasPurpose(p, LegalBasisLegitimateInterest))) {
+			return false
+		}
+
+		switch {
+		case r.requireConsent || r.requireLI:
+		case !r.restricted:
+			if !v.hasVendor(id, legalBasis) || !v.hasPurpose(p, legalBasis) {
+				return false
+			}
+		default:
+			if !(v.IsVendorAllowed(id) || v.IsVendorLIAllowed(id)) ||
+				!(v.hasPurpose(p, LegalBasisConsent) || v.hasPurpose(p, LegalBasisLegitimateInterest)) {
+				return false
+			}
+		}
+	}
+
+	// Without purposes, either signal of the vendor is enough
+	if len(purposeIds) == 0 {
+		return v.IsVendorAllowed(id) || v.IsVendorLIAllowed(id)
+	}
+
+	return true
+}
+
+func (v *CoreStringView) hasVendor(id int, legalBasis LegalBasis) bool {
+	if legalBasis == LegalBasisLegitimateInterest {
+		return v.IsVendorLIAllowed(id)
+	}
+	return v.IsVendorAllowed(id)
+}
+
+func (v *CoreStringView) hasPurpose(id int, legalBasis LegalBasis) bool {
+	if legalBasis == LegalBasisLegitimateInterest {
+		return v.policyRules().AllowsLegitimateInterest(id) && v.IsPurposeLIAllowed(id)
+	}
+	return v.IsPurposeAllowed(id)
+}
+
+func (v *CoreStringView) policyRules() *PolicyRules {
+	if v.PolicyRules != nil {
+		return v.PolicyRules
+	}
+	return policyRulesForVersion(v.TcfPolicyVersion())
+}
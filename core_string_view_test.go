@@ -0,0 +1,199 @@
+package iabtcfv2
+
+import (
+	"errors"
+	"testing"
+)
+
+var viewTestStrings = []string{
+	"COxR03kOxR1CqBcABCENAgCMAP_AAH_AAAqIF3EXySoGY2thI2YVFxBEIYwfJxyigMgChgQIsSwNQIeFLBoGLiAAHBGYJAQAGBAEEACBAQIkHGBMCQAAgAgBiRCMQEGMCzNIBIBAggEbY0FACCVmHkHSmZCY7064O__QLuIJEFQMAkSBAIACLECIQwAQDiAAAYAlAAABAhIaAAgIWBQEeAAAACAwAAgAAABBAAACAAQAAICIAAABAAAgAiAQAAAAGgIQAACBABACRIAAAEANCAAgiCEAQg4EAo4AAA",
+	"CPTZZYAPTZZYABcABCENAgCMAPzAAEPAAAqIDaQBQAMgAgABqAR0A2gDaQAwAMgAgANoAAA.IDaQBQAMgAgABqAR0A2g.QDaQAgAMwAgADUA.eEAAAAAAAUA",
+	"CPStgrQPStgrQAGABCDEB9CsAP_AAH_AAAqIH-NN7S__a2Pj-359Q_t0eY1f9953v-UhjhaZk6QF0bPDsL8V4mM6vE3opioKuBYEO3LAIQRlHKHcBQGAaokRoTPsbk2MLpAAJ7PEmgMbEmdIGHV9m93DnZKYz3w-2r6T_u4NRP_M5MfpP41v3Wt5tl06qXTTVz8YhLP1cAABAAAAQPiAIEBAUAgAEMAEQAFCIQAAQpiQAAAABBCABAAAAIiAAQVwAZIIEAAARAAAQAABAQgwAAAAAABCAAAACwQCAACAQAAgAEAAAAEJAIBACAEAAAEAJABACACECAggAAAwDAgAACCABABAAACJDAAAMIIASABgBEAABEgAGAAACAoMgFgBMAEcAMsAfYBWwExAJsAWwAz4BygD4hEAkAZYBTwDqgHyAQ6AkQBNgDPgHKCQAIDfxAAEAEgSBUAAgABYAFQAMgAcAA8ACAAGUANAA1AB5AEQARQAmABvADmAHoAP0AiACJAEsAJoAUoAtwBhwDKAMsAaoA-wB-gEUAKeAbQA3AB8gEOgJEATEAmwBTQC2AGSAM-AaQA1iByYHKBQAYAigBfAO3CAAwASAGiAU-GgGgBcAGWAQUAp8BaAFpAOqAfIBDoCRAE2AMYAZ8A5QOABAb-KgGABMAC4AI4AZcBaAFpASCAmIBNgCmwFsAM-AcoOgZAALAAqABkADgAIIAYgBlADQANQAeAA-gCIAIoATAAuABiADMAG8AOYAegA_ACIAEsAJgATQAowBSgC3AGGAMoAaIA-wB-gEUAKfAWgBaQC8gG4AOoAh0BIICRAE2AKagWwBbIDGAGSAMsAZmAz4BpADWIHJgcoPADAAqAEUAL4AjIDfwHbjgAIAJCEBYABYAGQAYgBMAC4AGIAMwAbwA9ACOAH2ARQAoYBT4C0ALSAdQBIICRAE2AKagWwBbIDPiIAMAFQAvgCMkoEAACAAFgAZAA4AB8AGIAPAAiABMAC4AGIAMwAbYBEAESAKMAUoAtwBqgEnAKfAWgBaQDcAHUAPkAh0BIgCbAFsAMsAZ8A0gBrBMAEARkBv5SBQAAsACoAGQAOAAggBiAGUANAA1AB5AEQARQAmABSADEAGYAOYAfgBEACjAFKALcAZQA0QBqgD7AKGAVsAvIBtADcAIdASIAk4BNgC2AGMAMkAZYAz4BpADWIHJgcoVACAAqAB8AL4Bv5QAGACQAk4BOw.YAAAAAAAAAAA",
+}
+
+func TestCoreStringView(t *testing.T) {
+	var v CoreStringView
+	purposeIds := [][]int{{}, {1}, {2}, {1, 3}, {2, 7, 10}, {3, 4, 5, 6}}
+
+	for _, str := range viewTestStrings {
+		data, err := Decode(str)
+		if err != nil {
+			t.Errorf("TC String should be decoded without error: %s", err)
+			return
+		}
+		c := data.CoreString
+
+		if err := v.Reset(str); err != nil {
+			t.Errorf("View should be reset without error: %s", err)
+			return
+		}
+
+		if v.Version() != c.Version || v.CmpId() != c.CmpId || v.CmpVersion() != c.CmpVersion ||
+			v.VendorListVersion() != c.VendorListVersion || v.TcfPolicyVersion() != c.TcfPolicyVersion ||
+			v.IsServiceSpecific() != c.IsServiceSpecific || v.PurposeOneTreatment() != c.PurposeOneTreatment ||
+			!v.Created().Equal(c.Created) || !v.LastUpdated().Equal(c.LastUpdated) ||
+			v.MaxVendorId() != c.MaxVendorId || v.MaxVendorIdLI() != c.MaxVendorIdLI {
+			t.Errorf("View fields should match the decoded core string")
+		}
+
+		for id := 0; id <= 25; id++ {
+			if v.IsSpecialFeatureAllowed(id) != c.IsSpecialFeatureAllowed(id) ||
+				v.IsPurposeAllowed(id) != c.IsPurposeAllowed(id) ||
+				v.IsPurposeLIAllowed(id) != c.IsPurposeLIAllowed(id) {
+				t.Errorf("Purpose %d should match the decoded core string", id)
+			}
+		}
+
+		for id := 0; id <= 1000; id++ {
+			if v.IsVendorAllowed(id) != c.IsVendorAllowed(id) || v.IsVendorLIAllowed(id) != c.IsVendorLIAllowed(id) {
+				t.Errorf("Vendor %d should match the decoded core string", id)
+				return
+			}
+			for _, p := range purposeIds {
+				if v.IsVendorAllowedForPurposes(id, p...) != c.IsVendorAllowedForPurposes(id, p...) ||
+					v.IsVendorAllowedForPurposesLI(id, p...) != c.IsVendorAllowedForPurposesLI(id, p...) ||
+					v.IsVendorAllowedForFlexiblePurposes(id, p...) != c.IsVendorAllowedForFlexiblePurposes(id, p...) ||
+					v.IsVendorAllowedForFlexiblePurposesLI(id, p...) != c.IsVendorAllowedForFlexiblePurposesLI(id, p...) {
+					t.Errorf("Vendor %d for purposes %v should match the decoded core string", id, p)
+					return
+				}
+			}
+		}
+	}
+}
+
+func TestCoreStringViewRestrictions(t *testing.T) {
+	c := &CoreString{
+		Version:                int(TcfVersion2),
+		Created:                timeFromDeciSeconds(16431552000),
+		LastUpdated:            timeFromDeciSeconds(16431552000),
+		ConsentLanguage:        "EN",
+		PublisherCC:            "FR",
+		PurposesConsent:        map[int]bool{1: true, 2: true, 3: true},
+		PurposesLITransparency: map[int]bool{2: true, 7: true},
+		NumPubRestrictions:     3,
+		PubRestrictions: []*PubRestriction{
+			{PurposeId: 1, RestrictionType: RestrictionTypeNotAllowed, NumEntries: 1, RangeEntries: []*RangeEntry{{StartVendorID: 10, EndVendorID: 10}}},
+			{PurposeId: 2, RestrictionType: RestrictionTypeRequireConsent, NumEntries: 1, RangeEntries: []*RangeEntry{{StartVendorID: 1, EndVendorID: 20}}},
+			{PurposeId: 7, RestrictionType: RestrictionTypeRequireLI, NumEntries: 2, RangeEntries: []*RangeEntry{{StartVendorID: 5, EndVendorID: 5}, {StartVendorID: 30, EndVendorID: 40}}},
+		},
+	}
+	c.SetVendorsConsent(1, 5, 10, 35)
+	c.SetVendorsLITransparency(5, 10, 35)
+
+	v, err := NewCoreStringView(c.Encode())
+	if err != nil {
+		t.Errorf("View should be created without error: %s", err)
+		return
+	}
+
+	for id := 0; id <= 50; id++ {
+		for _, p := range [][]int{{}, {1}, {2}, {7}, {1, 2}, {2, 7}} {
+			if v.IsVendorAllowedForPurposes(id, p...) != c.IsVendorAllowedForPurposes(id, p...) ||
+				v.IsVendorAllowedForPurposesLI(id, p...) != c.IsVendorAllowedForPurposesLI(id, p...) ||
+				v.IsVendorAllowedForFlexiblePurposes(id, p...) != c.IsVendorAllowedForFlexiblePurposes(id, p...) ||
+				v.IsVendorAllowedForFlexiblePurposesLI(id, p...) != c.IsVendorAllowedForFlexiblePurposesLI(id, p...) {
+				t.Errorf("Vendor %d for purposes %v should match the core string", id, p)
+			}
+		}
+	}
+}
+
+func TestCoreStringViewOtherVendorRestricted(t *testing.T) {
+	c := &CoreString{
+		Version:                int(TcfVersion2),
+		Created:                timeFromDeciSeconds(16431552000),
+		LastUpdated:            timeFromDeciSeconds(16431552000),
+		ConsentLanguage:        "EN",
+		PublisherCC:            "FR",
+		PurposesLITransparency: map[int]bool{2: true},
+		NumPubRestrictions:     1,
+		PubRestrictions: []*PubRestriction{
+			{PurposeId: 2, RestrictionType: RestrictionTypeRequireConsent, NumEntries: 1, RangeEntries: []*RangeEntry{{StartVendorID: 10, EndVendorID: 10}}},
+		},
+	}
+	c.SetVendorsLITransparency(1)
+
+	v, err := NewCoreStringView(c.Encode())
+	if err != nil {
+		t.Errorf("View should be created without error: %s", err)
+		return
+	}
+
+	// Restrictions of purpose 2 don't apply to vendor 1, so either signal is enough
+	if !v.IsVendorAllowedForFlexiblePurposes(1, 2) || !v.IsVendorAllowedForFlexiblePurposesLI(1, 2) {
+		t.Errorf("Vendor 1 should be allowed for flexible purpose 2")
+	}
+}
+
+func TestCoreStringViewErrors(t *testing.T) {
+	_, err := NewCoreStringView("COxR03kOxR1CqBcABCENAgCMAP_AAH_AAAqIF3EXySoGY2thI2YVFxBEIYwfJxyigMgChgQIsSwNQIeFLB")
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || !errors.Is(err, ErrTruncated) || decodeErr.Field != "VendorsConsent" {
+		t.Errorf("Truncated core string should return a DecodeError: %v", err)
+	}
+
+	_, err = NewCoreStringView("COxR03kOxR1C*")
+	if !errors.Is(err, ErrBadBase64) {
+		t.Errorf("Invalid base64 should return ErrBadBase64: %v", err)
+	}
+
+	var v CoreStringView
+	if err := v.Reset(viewTestStrings[0]); err != nil {
+		t.Errorf("View should be reset without error: %s", err)
+		return
+	}
+	if err := v.Reset("A"); err == nil || v.IsPurposeAllowed(1) || v.IsVendorAllowed(1) || v.Version() != 0 {
+		t.Errorf("View should be empty after a failed reset")
+	}
+}
+
+func TestCoreStringViewAllocations(t *testing.T) {
+	var v CoreStringView
+	str := viewTestStrings[2]
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = v.Reset(str)
+		_ = v.IsVendorAllowed(755)
+		_ = v.IsPurposeAllowed(1)
+		_ = v.IsVendorAllowedForFlexiblePurposes(755, 1, 2, 7)
+	})
+	if allocs != 0 {
+		t.Errorf("Reset and checks should not allocate: %v allocations", allocs)
+	}
+}
+
+func BenchmarkDecodeCoreString(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c, err := DecodeCoreString(viewTestStrings[2][:len(viewTestStrings[2])-len(".YAAAAAAAAAAA")])
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = c.IsVendorAllowedForFlexiblePurposes(755, 1, 2, 7)
+	}
+}
+
+func BenchmarkCoreStringView(b *testing.B) {
+	b.ReportAllocs()
+	var v CoreStringView
+	for i := 0; i < b.N; i++ {
+		if err := v.Reset(viewTestStrings[2]); err != nil {
+			b.Fatal(err)
+		}
+		_ = v.IsVendorAllowedForFlexiblePurposes(755, 1, 2, 7)
+	}
+}
+
+func BenchmarkCoreStringViewChecks(b *testing.B) {
+	b.ReportAllocs()
+	v, err := NewCoreStringView(viewTestStrings[2])
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = v.IsVendorAllowed(755)
+		_ = v.IsPurposeAllowed(1)
+		_ = v.IsVendorAllowedForFlexiblePurposes(755, 1, 2, 7)
+	}
+}
@@ -0,0 +1,142 @@
+package iabtcfv2
+
+// Decision details how a legal basis check was evaluated
+type Decision struct {
+	Allowed           bool               `json:"allowed"`
+	LegalBasis        LegalBasis         `json:"legalBasis"`
+	PurposeLegalBases map[int]LegalBasis `json:"purposeLegalBases,omitempty"`
+	Checks            []*Check           `json:"checks"`
+	Failed            *Check             `json:"failed,omitempty"`
+}
+
+// Check is a single signal or publisher restriction evaluated for a Decision
+// Field is the name of the structure field holding the signal
+type Check struct {
+	Field           string           `json:"field"`
+	Id              int              `json:"id"`
+	PurposeId       int              `json:"purposeId,omitempty"`
+	RestrictionType *RestrictionType `json:"restrictionType,omitempty"`
+	Passed          bool             `json:"passed"`
+}
+
+const (
+	fieldSpecialFeatureOptIns         = "SpecialFeatureOptIns"
+	fieldPurposesConsent              = "PurposesConsent"
+	fieldPurposesLITransparency       = "PurposesLITransparency"
+	fieldVendorsConsent               = "VendorsConsent"
+	fieldVendorsLITransparency        = "VendorsLITransparency"
+	fieldPubRestrictions              = "PubRestrictions"
+	fieldPubPurposesConsent           = "PubPurposesConsent"
+	fieldPubPurposesLITransparency    = "PubPurposesLITransparency"
+	fieldCustomPurposesConsent        = "CustomPurposesConsent"
+	fieldCustomPurposesLITransparency = "CustomPurposesLITransparency"
+	fieldVendorListVendors            = "VendorList.Vendors"
+	fieldVendorPurposes               = "Vendor.Purposes"
+	fieldVendorLegIntPurposes         = "Vendor.LegIntPurposes"
+	fieldVendorFlexiblePurposes       = "Vendor.FlexiblePurposes"
+	fieldVendorSpecialPurposes        = "Vendor.SpecialPurposes"
+
+	fieldPolicyNoLegitimateInterestPurposes = "PolicyRules.NoLegitimateInterestPurposes"
+)
+
+// Records a check and returns its result
+// All recording methods are no-op on a nil Decision so that Is* functions can share the evaluation without allocating
+func (d *Decision) check(field string, id int, passed bool) bool {
+	if d == nil {
+		return passed
+	}
+
+	c := &Check{Field: field, Id: id, Passed: passed}
+	d.Checks = append(d.Checks, c)
+	if !passed && d.Failed == nil {
+		d.Failed = c
+	}
+	return passed
+}
+
+func (d *Decision) checkRestriction(r *PubRestriction, id int, passed bool) bool {
+	if d == nil {
+		return passed
+	}
+
+	restrictionType := r.RestrictionType
+	c := &Check{Field: fieldPubRestrictions, Id: id, PurposeId: r.PurposeId, RestrictionType: &restrictionType, Passed: passed}
+	d.Checks = append(d.Checks, c)
+	if !passed && d.Failed == nil {
+		d.Failed = c
+	}
+	return passed
+}
+
+// Records the legal basis used for purpose id
+func (d *Decision) use(purposeId int, legalBasis LegalBasis) {
+	if d == nil {
+		return
+	}
+
+	if d.PurposeLegalBases == nil {
+		d.PurposeLegalBases = make(map[int]LegalBasis)
+	}
+	d.PurposeLegalBases[purposeId] = legalBasis
+}
+
+// Marks the decision as allowed
+// The legal basis is the one shared by all purposes, or legalBasis if they differ
+func (d *Decision) allow(legalBasis LegalBasis) bool {
+	if d == nil {
+		return true
+	}
+
+	d.Allowed = true
+	d.LegalBasis = legalBasis
+	var shared LegalBasis
+	for _, b := range d.PurposeLegalBases {
+		if shared != LegalBasisNone && shared != b {
+			return true
+		}
+		shared = b
+	}
+	if shared != LegalBasisNone {
+		d.LegalBasis = shared
+	}
+	return true
+}
+
+func (d *Decision) deny() bool {
+	if d != nil {
+		d.Allowed = false
+		d.LegalBasis = LegalBasisNone
+		d.PurposeLegalBases = nil
+	}
+	return false
+}
+
+func (l LegalBasis) String() string {
+	switch l {
+	case LegalBasisConsent:
+		return "consent"
+	case LegalBasisLegitimateInterest:
+		return "legitimateInterest"
+	}
+	return "none"
+}
+
+func (l LegalBasis) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func (r RestrictionType) String() string {
+	switch r {
+	case RestrictionTypeNotAllowed:
+		return "notAllowed"
+	case RestrictionTypeRequireConsent:
+		return "requireConsent"
+	case RestrictionTypeRequireLI:
+		return "requireLI"
+	}
+	return "undefined"
+}
+
+func (r RestrictionType) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
@@ -0,0 +1,114 @@
+package iabtcfv2
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExplainVendorAllowedForPurposes(t *testing.T) {
+	c := &CoreString{
+		PurposesConsent: map[int]bool{1: true, 2: true},
+		VendorsConsent:  map[int]bool{10: true},
+		PubRestrictions: []*PubRestriction{
+			{PurposeId: 2, RestrictionType: RestrictionTypeNotAllowed, NumEntries: 1, RangeEntries: []*RangeEntry{{StartVendorID: 10, EndVendorID: 10}}},
+		},
+	}
+
+	d := c.ExplainVendorAllowedForPurposes(10, 1)
+	if !d.Allowed || d.LegalBasis != LegalBasisConsent || d.Failed != nil {
+		t.Errorf("Vendor 10 should be allowed for purpose 1 under consent")
+	}
+
+	d = c.ExplainVendorAllowedForPurposes(10, 1, 2)
+	if d.Allowed || d.Failed == nil {
+		t.Errorf("Vendor 10 should not be allowed for purpose 2")
+		return
+	}
+
+	if d.Failed.Field != fieldPubRestrictions || d.Failed.PurposeId != 2 || *d.Failed.RestrictionType != RestrictionTypeNotAllowed {
+		t.Errorf("Decision should fail on the publisher restriction of purpose 2: %+v", d.Failed)
+	}
+
+	d = c.ExplainVendorAllowedForPurposes(11, 1)
+	if d.Allowed || d.Failed == nil || d.Failed.Field != fieldVendorsConsent || d.Failed.Id != 11 {
+		t.Errorf("Decision should fail on vendor 11 consent")
+	}
+
+	if c.IsVendorAllowedForPurposes(10, 1, 2) != c.ExplainVendorAllowedForPurposes(10, 1, 2).Allowed {
+		t.Errorf("IsVendorAllowedForPurposes and ExplainVendorAllowedForPurposes should agree")
+	}
+}
+
+func TestExplainVendorAllowedForFlexiblePurposes(t *testing.T) {
+	c := &CoreString{
+		PurposesConsent:        map[int]bool{2: true},
+		PurposesLITransparency: map[int]bool{7: true},
+		VendorsConsent:         map[int]bool{10: true},
+		VendorsLITransparency:  map[int]bool{10: true},
+		PubRestrictions: []*PubRestriction{
+			{PurposeId: 7, RestrictionType: RestrictionTypeRequireLI, NumEntries: 1, RangeEntries: []*RangeEntry{{StartVendorID: 10, EndVendorID: 10}}},
+		},
+	}
+
+	d := c.ExplainVendorAllowedForFlexiblePurposes(10, 2, 7)
+	if !d.Allowed {
+		t.Errorf("Vendor 10 should be allowed for purposes 2 and 7: %+v", d.Failed)
+		return
+	}
+
+	if d.PurposeLegalBases[2] != LegalBasisConsent || d.PurposeLegalBases[7] != LegalBasisLegitimateInterest {
+		t.Errorf("Purpose 2 should use consent and purpose 7 legitimate interest")
+	}
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Errorf("Decision should be marshaled without error: %s", err)
+		return
+	}
+
+	if !strings.Contains(string(b), `"restrictionType":"requireLI"`) || !strings.Contains(string(b), `"legalBasis":"consent"`) {
+		t.Errorf("Decision JSON should name restriction types and legal bases: %s", b)
+	}
+}
+
+func TestEnforcerExplainLegalBasis(t *testing.T) {
+	e := newTestEnforcer(t,
+		&PubRestriction{PurposeId: 7, RestrictionType: RestrictionTypeRequireConsent, NumEntries: 1, RangeEntries: []*RangeEntry{{StartVendorID: 2, EndVendorID: 2}}},
+	)
+
+	d := e.ExplainLegalBasis(2, 7)
+	if d.Allowed || d.Failed == nil || d.Failed.Field != fieldVendorFlexiblePurposes {
+		t.Errorf("Decision should fail because purpose 7 is not flexible for vendor 2")
+	}
+
+	d = e.ExplainLegalBasis(1, 2)
+	if !d.Allowed || d.LegalBasis != LegalBasisLegitimateInterest {
+		t.Errorf("Vendor 1 should process purpose 2 under legitimate interest")
+	}
+}
+
+func TestVendorAllowedForFlexiblePurposesOtherVendorRestricted(t *testing.T) {
+	c := &CoreString{
+		PurposesConsent:        map[int]bool{3: true},
+		PurposesLITransparency: map[int]bool{2: true},
+		VendorsLITransparency:  map[int]bool{1: true},
+		PubRestrictions: []*PubRestriction{
+			{PurposeId: 2, RestrictionType: RestrictionTypeRequireConsent, NumEntries: 1, RangeEntries: []*RangeEntry{{StartVendorID: 10, EndVendorID: 10}}},
+		},
+	}
+
+	// Restrictions of purpose 2 don't apply to vendor 1, so either signal is enough
+	d := c.ExplainVendorAllowedForFlexiblePurposes(1, 2)
+	if !d.Allowed || d.PurposeLegalBases[2] != LegalBasisLegitimateInterest {
+		t.Errorf("Vendor 1 should be allowed for purpose 2 under legitimate interest: %+v", d.Failed)
+	}
+	if !c.IsVendorAllowedForFlexiblePurposes(1, 2) || !c.IsVendorAllowedForFlexiblePurposesLI(1, 2) {
+		t.Errorf("Vendor 1 should be allowed for flexible purpose 2")
+	}
+
+	// Without restrictions, the default legal basis is required
+	if c.IsVendorAllowedForFlexiblePurposes(1, 3) {
+		t.Errorf("Vendor 1 should not be allowed for purpose 3 without vendor consent")
+	}
+}
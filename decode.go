@@ -1,7 +1,7 @@
 package iabtcfv2
 
 import (
-	"encoding/base64"
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -12,217 +12,315 @@ import (
 // - TcfVersion1 = 1
 // - TcfVersion2 = 2
 func GetVersion(s string) (version TcfVersion, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
-		}
-	}()
-
 	segments := strings.Split(s, ".")
-	if len(segments) == 0 {
-		return TcfVersionUndefined, err
-	}
 
-	b, err := base64.RawURLEncoding.DecodeString(segments[0])
+	d, err := newSegmentDecoder(SegmentTypeUndefined, segments[0])
 	if err != nil {
 		return TcfVersionUndefined, err
 	}
 
-	var e = NewTCEncoder(b)
-	return TcfVersion(e.ReadInt(bitsVersion)), nil
+	version = TcfVersion(d.readInt("Version", bitsVersion))
+	if d.err != nil {
+		return TcfVersionUndefined, d.err
+	}
+
+	return version, nil
 }
 
 // Decodes a segment value and returns the SegmentType
 // - SegmentTypeUndefined = -1
 // - SegmentTypeCoreString = 0
 // - SegmentTypeDisclosedVendors = 1
+// - SegmentTypeAllowedVendors = 2
 // - SegmentTypePublisherTC = 3
 func GetSegmentType(segment string) (segmentType SegmentType, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
-		}
-	}()
-
-	b, err := base64.RawURLEncoding.DecodeString(segment)
+	d, err := newSegmentDecoder(SegmentTypeUndefined, segment)
 	if err != nil {
 		return SegmentTypeUndefined, err
 	}
 
-	var e = NewTCEncoder(b)
-	return SegmentType(e.ReadInt(bitsSegmentType)), nil
+	segmentType = SegmentType(d.readInt("SegmentType", bitsSegmentType))
+	if d.err != nil {
+		return SegmentTypeUndefined, d.err
+	}
+
+	return segmentType, nil
+}
+
+type DecodeMode int
+
+const (
+	// Malformed optional segments and invalid values are reported as warnings
+	DecodeModeLenient DecodeMode = 0
+	// Malformed segments and invalid values fail the decoding
+	DecodeModeStrict DecodeMode = 1
+)
+
+type DecodeOptions struct {
+	Mode DecodeMode
 }
 
 // Decode a TC String and returns it as a TCData structure
 // A valid TC String must start with a Core String segment
 // A TC String can optionally and arbitrarily ordered contain:
 // - Disclosed Vendors
+// - Allowed Vendors
 // - Publisher TC
+// Malformed optional segments are ignored, see DecodeWithOptions to get the warnings
 func Decode(tcString string) (t *TCData, err error) {
+	t, _, err = DecodeWithOptions(tcString, DecodeOptions{Mode: DecodeModeLenient})
+	return t, err
+}
+
+// Decode a TC String with options and returns it as a TCData structure
+// In DecodeModeStrict, an error is returned for any malformed segment, unknown segment type,
+// non-zero padding bits, core string version other than 2, or vendor id above max vendor id
+// In DecodeModeLenient, these are returned as warnings with the TCData decoded so far
+// Both modes fail if the Core String is missing or malformed, or if a segment is duplicated
+func DecodeWithOptions(tcString string, opts DecodeOptions) (t *TCData, warnings []error, err error) {
 	t = &TCData{}
 	mapSegments := map[SegmentType]bool{}
 	for i, v := range strings.Split(tcString, ".") {
 		segmentType, err := GetSegmentType(v)
+		if err == nil && i == 0 && segmentType != SegmentTypeCoreString {
+			err = &DecodeError{SegmentType: SegmentTypeCoreString, Err: ErrMissingCore}
+		}
+		if err == nil && mapSegments[segmentType] {
+			err = &DecodeError{SegmentType: segmentType, Err: ErrDuplicateSegment}
+		}
 		if err != nil {
-			return nil, err
+			if i == 0 || errors.Is(err, ErrDuplicateSegment) || opts.Mode == DecodeModeStrict {
+				return nil, nil, err
+			}
+			warnings = append(warnings, err)
+			continue
 		}
 
-		switch segmentType {
-		case SegmentTypeDisclosedVendors:
-			if mapSegments[SegmentTypeDisclosedVendors] == true {
-				return nil, fmt.Errorf("duplicate Disclosed Vendors segment")
-			}
-			segment, err := DecodeDisclosedVendors(v)
-			if err == nil {
-				t.DisclosedVendors = segment
-				mapSegments[SegmentTypeDisclosedVendors] = true
-			}
-			break
-		case SegmentTypePublisherTC:
-			if mapSegments[SegmentTypePublisherTC] == true {
-				return nil, fmt.Errorf("duplicate Publisher TC segment")
-			}
-			segment, err := DecodePublisherTC(v)
-			if err == nil {
-				t.PublisherTC = segment
-				mapSegments[SegmentTypePublisherTC] = true
-			}
-			break
-		default:
-			if mapSegments[SegmentTypeCoreString] == true {
-				return nil, fmt.Errorf("duplicate Core String segment")
-			}
-			segment, err := DecodeCoreString(v)
-			if err == nil {
-				t.CoreString = segment
-				if i == 0 {
-					mapSegments[SegmentTypeCoreString] = true
-				}
+		issues, err := t.decodeSegment(segmentType, v)
+		if err == nil && opts.Mode == DecodeModeStrict && len(issues) > 0 {
+			err = issues[0]
+		}
+		if err != nil {
+			if i == 0 || opts.Mode == DecodeModeStrict {
+				return nil, nil, err
 			}
-			break
+			warnings = append(warnings, err)
+			continue
 		}
+		warnings = append(warnings, issues...)
+		mapSegments[segmentType] = true
 	}
 
-	if mapSegments[SegmentTypeCoreString] == false {
-		return nil, fmt.Errorf("invalid TC string")
+	return t, warnings, nil
+}
+
+// Decodes segment into the matching field of t and returns the issues found in its values
+func (t *TCData) decodeSegment(segmentType SegmentType, segment string) (issues []error, err error) {
+	d, err := newSegmentDecoder(segmentType, segment)
+	if err != nil {
+		return nil, err
 	}
 
-	return t, nil
+	switch segmentType {
+	case SegmentTypeCoreString:
+		c := d.decodeCoreString()
+		if d.err != nil {
+			return nil, d.err
+		}
+		t.CoreString = c
+		if c.Version != int(TcfVersion2) {
+			issues = append(issues, &DecodeError{SegmentType: segmentType, Field: "Version", Err: fmt.Errorf("%w: %d", ErrUnsupportedVersion, c.Version)})
+		}
+		if c.IsRangeEncoding {
+			issues = appendVendorIdIssues(issues, segmentType, "RangeEntries", c.MaxVendorId, c.RangeEntries)
+		}
+		if c.IsRangeEncodingLI {
+			issues = appendVendorIdIssues(issues, segmentType, "RangeEntriesLI", c.MaxVendorIdLI, c.RangeEntriesLI)
+		}
+	case SegmentTypeDisclosedVendors:
+		dv := d.decodeDisclosedVendors()
+		if d.err != nil {
+			return nil, d.err
+		}
+		t.DisclosedVendors = dv
+		if dv.IsRangeEncoding {
+			issues = appendVendorIdIssues(issues, segmentType, "RangeEntries", dv.MaxVendorId, dv.RangeEntries)
+		}
+	case SegmentTypeAllowedVendors:
+		a := d.decodeAllowedVendors()
+		if d.err != nil {
+			return nil, d.err
+		}
+		t.AllowedVendors = a
+		if a.IsRangeEncoding {
+			issues = appendVendorIdIssues(issues, segmentType, "RangeEntries", a.MaxVendorId, a.RangeEntries)
+		}
+	case SegmentTypePublisherTC:
+		p := d.decodePublisherTC()
+		if d.err != nil {
+			return nil, d.err
+		}
+		t.PublisherTC = p
+	default:
+		return nil, &DecodeError{SegmentType: segmentType, Field: "SegmentType", Err: ErrUnknownSegmentType}
+	}
+
+	if err := d.checkPadding(segment); err != nil {
+		issues = append(issues, err)
+	}
+
+	return issues, nil
 }
 
-// Decodes a Core String value and returns it as a CoreString structure
-func DecodeCoreString(coreString string) (c *CoreString, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
+func appendVendorIdIssues(issues []error, segmentType SegmentType, field string, maxVendorId int, entries []*RangeEntry) []error {
+	for _, entry := range entries {
+		if entry.EndVendorID > maxVendorId {
+			return append(issues, &DecodeError{SegmentType: segmentType, Field: field, Err: fmt.Errorf("%w: %d > %d", ErrVendorIdOutOfRange, entry.EndVendorID, maxVendorId)})
 		}
-	}()
+	}
+	return issues
+}
 
-	b, err := base64.RawURLEncoding.DecodeString(coreString)
+// Decodes a Core String value and returns it as a CoreString structure
+func DecodeCoreString(coreString string) (c *CoreString, err error) {
+	d, err := newSegmentDecoder(SegmentTypeCoreString, coreString)
 	if err != nil {
 		return nil, err
 	}
 
-	var e = NewTCEncoder(b)
-
-	c = &CoreString{}
-	c.Version = e.ReadInt(bitsVersion)
-	c.Created = e.ReadTime()
-	c.LastUpdated = e.ReadTime()
-	c.CmpId = e.ReadInt(bitsCmpId)
-	c.CmpVersion = e.ReadInt(bitsCmpVersion)
-	c.ConsentScreen = e.ReadInt(bitsConsentScreen)
-	c.ConsentLanguage = e.ReadChars(bitsConsentLanguage)
-	c.VendorListVersion = e.ReadInt(bitsVendorListVersion)
-	c.TcfPolicyVersion = e.ReadInt(bitsTcfPolicyVersion)
-	c.IsServiceSpecific = e.ReadBool()
-	c.UseNonStandardTexts = e.ReadBool()
-	c.SpecialFeatureOptIns = e.ReadBitField(bitsSpecialFeatureOptIns)
-	c.PurposesConsent = e.ReadBitField(bitsPurposesConsent)
-	c.PurposesLITransparency = e.ReadBitField(bitsPurposesLITransparency)
-	c.PurposeOneTreatment = e.ReadBool()
-	c.PublisherCC = e.ReadChars(bitsPublisherCC)
-
-	c.MaxVendorId = e.ReadInt(bitsMaxVendorId)
-	c.IsRangeEncoding = e.ReadBool()
-	if c.IsRangeEncoding {
-		c.NumEntries, c.RangeEntries = e.ReadRangeEntries()
-	} else {
-		c.VendorsConsent = e.ReadBitField(uint(c.MaxVendorId))
-	}
-
-	c.MaxVendorIdLI = e.ReadInt(bitsMaxVendorId)
-	c.IsRangeEncodingLI = e.ReadBool()
-	if c.IsRangeEncodingLI {
-		c.NumEntriesLI, c.RangeEntriesLI = e.ReadRangeEntries()
-	} else {
-		c.VendorsLITransparency = e.ReadBitField(uint(c.MaxVendorIdLI))
+	c = d.decodeCoreString()
+	if d.err != nil {
+		return nil, d.err
 	}
 
-	c.NumPubRestrictions, c.PubRestrictions = e.ReadPubRestrictions()
-
 	return c, nil
 }
 
 // Decodes a Disclosed Vendors value and returns it as a DisclosedVendors structure
-func DecodeDisclosedVendors(disclosedVendors string) (d *DisclosedVendors, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
-		}
-	}()
-
-	b, err := base64.RawURLEncoding.DecodeString(disclosedVendors)
+func DecodeDisclosedVendors(disclosedVendors string) (dv *DisclosedVendors, err error) {
+	d, err := newSegmentDecoder(SegmentTypeDisclosedVendors, disclosedVendors)
 	if err != nil {
 		return nil, err
 	}
 
-	var e = NewTCEncoder(b)
-
-	d = &DisclosedVendors{}
-	d.SegmentType = e.ReadInt(bitsSegmentType)
-	d.MaxVendorId = e.ReadInt(bitsMaxVendorId)
-	d.IsRangeEncoding = e.ReadBool()
-	if d.IsRangeEncoding {
-		d.NumEntries, d.RangeEntries = e.ReadRangeEntries()
-	} else {
-		d.DisclosedVendors = e.ReadBitField(uint(d.MaxVendorId))
+	dv = d.decodeDisclosedVendors()
+	if d.err != nil {
+		return nil, d.err
 	}
 
-	if d.SegmentType != int(SegmentTypeDisclosedVendors) {
-		err = fmt.Errorf("disclosed vendors segment type must be %d", SegmentTypeDisclosedVendors)
+	return dv, nil
+}
+
+// Decodes an Allowed Vendors value and returns it as an AllowedVendors structure
+func DecodeAllowedVendors(allowedVendors string) (a *AllowedVendors, err error) {
+	d, err := newSegmentDecoder(SegmentTypeAllowedVendors, allowedVendors)
+	if err != nil {
 		return nil, err
 	}
 
-	return d, nil
+	a = d.decodeAllowedVendors()
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return a, nil
 }
 
 // Decodes a Publisher TC value and returns it as a PublisherTC structure
 func DecodePublisherTC(publisherTC string) (p *PublisherTC, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
-		}
-	}()
-
-	b, err := base64.RawURLEncoding.DecodeString(publisherTC)
+	d, err := newSegmentDecoder(SegmentTypePublisherTC, publisherTC)
 	if err != nil {
 		return nil, err
 	}
 
-	var e = NewTCEncoder(b)
+	p = d.decodePublisherTC()
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return p, nil
+}
 
-	p = &PublisherTC{}
-	p.SegmentType = e.ReadInt(bitsSegmentType)
-	p.PubPurposesConsent = e.ReadBitField(bitsPubPurposesConsent)
-	p.PubPurposesLITransparency = e.ReadBitField(bitsPubPurposesLITransparency)
-	p.NumCustomPurposes = e.ReadInt(bitsNumCustomPurposes)
-	p.CustomPurposesConsent = e.ReadBitField(uint(p.NumCustomPurposes))
-	p.CustomPurposesLITransparency = e.ReadBitField(uint(p.NumCustomPurposes))
+func (d *segmentDecoder) decodeCoreString() *CoreString {
+	c := &CoreString{}
+	c.Version = d.readInt("Version", bitsVersion)
+	c.Created = d.readTime("Created")
+	c.LastUpdated = d.readTime("LastUpdated")
+	c.CmpId = d.readInt("CmpId", bitsCmpId)
+	c.CmpVersion = d.readInt("CmpVersion", bitsCmpVersion)
+	c.ConsentScreen = d.readInt("ConsentScreen", bitsConsentScreen)
+	c.ConsentLanguage = d.readChars("ConsentLanguage", bitsConsentLanguage)
+	c.VendorListVersion = d.readInt("VendorListVersion", bitsVendorListVersion)
+	c.TcfPolicyVersion = d.readInt("TcfPolicyVersion", bitsTcfPolicyVersion)
+	c.IsServiceSpecific = d.readBool("IsServiceSpecific")
+	c.UseNonStandardTexts = d.readBool("UseNonStandardTexts")
+	c.SpecialFeatureOptIns = d.readBitField("SpecialFeatureOptIns", bitsSpecialFeatureOptIns)
+	c.PurposesConsent = d.readBitField("PurposesConsent", bitsPurposesConsent)
+	c.PurposesLITransparency = d.readBitField("PurposesLITransparency", bitsPurposesLITransparency)
+	c.PurposeOneTreatment = d.readBool("PurposeOneTreatment")
+	c.PublisherCC = d.readChars("PublisherCC", bitsPublisherCC)
+
+	c.MaxVendorId = d.readInt("MaxVendorId", bitsMaxVendorId)
+	c.IsRangeEncoding = d.readBool("IsRangeEncoding")
+	if c.IsRangeEncoding {
+		c.NumEntries, c.RangeEntries = d.readRangeEntries("RangeEntries")
+	} else {
+		c.VendorsConsent = d.readBitField("VendorsConsent", uint(c.MaxVendorId))
+	}
+	c.VendorsConsentSet = newIDSetFromEncoding(c.IsRangeEncoding, c.VendorsConsent, c.RangeEntries)
 
-	if p.SegmentType != int(SegmentTypePublisherTC) {
-		return nil, fmt.Errorf("publisher TC segment type must be %d", SegmentTypePublisherTC)
+	c.MaxVendorIdLI = d.readInt("MaxVendorIdLI", bitsMaxVendorId)
+	c.IsRangeEncodingLI = d.readBool("IsRangeEncodingLI")
+	if c.IsRangeEncodingLI {
+		c.NumEntriesLI, c.RangeEntriesLI = d.readRangeEntries("RangeEntriesLI")
+	} else {
+		c.VendorsLITransparency = d.readBitField("VendorsLITransparency", uint(c.MaxVendorIdLI))
 	}
+	c.VendorsLITransparencySet = newIDSetFromEncoding(c.IsRangeEncodingLI, c.VendorsLITransparency, c.RangeEntriesLI)
 
-	return p, nil
+	c.NumPubRestrictions, c.PubRestrictions = d.readPubRestrictions("PubRestrictions")
+
+	return c
+}
+
+func (d *segmentDecoder) decodeDisclosedVendors() *DisclosedVendors {
+	dv := &DisclosedVendors{}
+	dv.SegmentType = d.readSegmentType()
+	dv.MaxVendorId = d.readInt("MaxVendorId", bitsMaxVendorId)
+	dv.IsRangeEncoding = d.readBool("IsRangeEncoding")
+	if dv.IsRangeEncoding {
+		dv.NumEntries, dv.RangeEntries = d.readRangeEntries("RangeEntries")
+	} else {
+		dv.DisclosedVendors = d.readBitField("DisclosedVendors", uint(dv.MaxVendorId))
+	}
+	dv.DisclosedVendorsSet = newIDSetFromEncoding(dv.IsRangeEncoding, dv.DisclosedVendors, dv.RangeEntries)
+
+	return dv
+}
+
+func (d *segmentDecoder) decodeAllowedVendors() *AllowedVendors {
+	a := &AllowedVendors{}
+	a.SegmentType = d.readSegmentType()
+	a.MaxVendorId = d.readInt("MaxVendorId", bitsMaxVendorId)
+	a.IsRangeEncoding = d.readBool("IsRangeEncoding")
+	if a.IsRangeEncoding {
+		a.NumEntries, a.RangeEntries = d.readRangeEntries("RangeEntries")
+	} else {
+		a.AllowedVendors = d.readBitField("AllowedVendors", uint(a.MaxVendorId))
+	}
+	a.AllowedVendorsSet = newIDSetFromEncoding(a.IsRangeEncoding, a.AllowedVendors, a.RangeEntries)
+
+	return a
+}
+
+func (d *segmentDecoder) decodePublisherTC() *PublisherTC {
+	p := &PublisherTC{}
+	p.SegmentType = d.readSegmentType()
+	p.PubPurposesConsent = d.readBitField("PubPurposesConsent", bitsPubPurposesConsent)
+	p.PubPurposesLITransparency = d.readBitField("PubPurposesLITransparency", bitsPubPurposesLITransparency)
+	p.NumCustomPurposes = d.readInt("NumCustomPurposes", bitsNumCustomPurposes)
+	p.CustomPurposesConsent = d.readBitField("CustomPurposesConsent", uint(p.NumCustomPurposes))
+	p.CustomPurposesLITransparency = d.readBitField("CustomPurposesLITransparency", uint(p.NumCustomPurposes))
+
+	return p
 }
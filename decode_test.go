@@ -1,6 +1,7 @@
 package iabtcfv2
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -177,6 +178,66 @@ func TestDecodeDisclosedVendors(t *testing.T) {
 	}
 }
 
+func TestDecodeAllowedVendors(t *testing.T) {
+	str := "QDaQAgAMwAgADUA"
+
+	segType, err := GetSegmentType(str)
+	if err != nil {
+		t.Errorf("Segment type should be decoded without error: %s", err)
+		return
+	}
+
+	if segType != SegmentTypeAllowedVendors {
+		t.Errorf("Segment type should be %d", SegmentTypeAllowedVendors)
+		return
+	}
+
+	segment, err := DecodeAllowedVendors(str)
+	if err != nil {
+		t.Errorf("Segment should be decoded without error: %s", err)
+		return
+	}
+
+	if segment.IsVendorAllowed(1) {
+		t.Errorf("Vendor 1 should not be allowed")
+		return
+	}
+
+	if !segment.IsVendorAllowed(40) {
+		t.Errorf("Vendor 40 should be allowed")
+		return
+	}
+
+	result := segment.Encode()
+	if result != str {
+		t.Errorf("Encode() should produce the same string: in = %s, out = %s", str, result)
+	}
+}
+
+func TestDecodeWithAllowedVendors(t *testing.T) {
+	str := "CPTZZYAPTZZYABcABCENAgCMAPzAAEPAAAqIDaQBQAMgAgABqAR0A2gDaQAwAMgAgANoAAA.IDaQBQAMgAgABqAR0A2g.QDaQAgAMwAgADUA.eEAAAAAAAUA"
+
+	data, err := Decode(str)
+	if err != nil {
+		t.Errorf("TC String should be decoded without error: %s", err)
+		return
+	}
+
+	if data.AllowedVendors == nil || data.CoreString == nil || data.DisclosedVendors == nil || data.PublisherTC == nil {
+		t.Errorf("TC String should be decoded with all its segments")
+		return
+	}
+
+	if !data.IsVendorAllowedOOB(25) || data.IsVendorAllowedOOB(26) {
+		t.Errorf("Only vendors 25 and 32 to 53 should be allowed to use OOB signaling")
+	}
+
+	result := data.ToTCString()
+	if result != str {
+		t.Errorf("Encode() should produce the same string: in = %s, out = %s", str, result)
+	}
+}
+
 func TestDecodePublisherTC(t *testing.T) {
 	str := "elAAAAAAAWA"
 
@@ -228,3 +289,94 @@ func TestPublisherRestrictionFlexibleVendor(t *testing.T) {
 		t.Errorf("flexible vendor 916 should be allowed to purpose 4 even with publisher restriction because consent is established on purpose 4 for this vendor")
 	}
 }
+
+func TestDecodeErrors(t *testing.T) {
+	str := "COxR03kOxR1CqBcABCENAgCMAP_AAH_AAAqIF3EXySoGY2thI2YVFxBEIYwfJxyigMgChgQIsSwNQIeFLBoGLiAAHBGYJAQAGBAEEACBAQIkHGBMCQAAgAgBiRCMQEGMCzNIBIBAggEbY0FACCVmHkHSmZCY7064O__QLuIJEFQMAkSBAIACLECIQwAQDiAAAYAlAAABAhIaAAgIWBQEeAAAACAwAAgAAABBAAACAAQAAICIAAABAAAgAiAQAAAAGgIQAACBABACRIAAAEANCAAgiCEAQg4EAo4AAA"
+
+	for i := 0; i < len(str)-4; i++ {
+		_, err := DecodeCoreString(str[:i])
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Errorf("Truncated core string of length %d should return a DecodeError: %v", i, err)
+			return
+		}
+		if !errors.Is(err, ErrTruncated) && !errors.Is(err, ErrBadBase64) {
+			t.Errorf("Truncated core string of length %d should be truncated or bad base64: %v", i, err)
+			return
+		}
+	}
+
+	_, err := DecodeCoreString("COxR03kOxR1CqBcABCENAgCMAP_AAH_AAAqIF3EXySoGY2thI2YVFxBE")
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || !errors.Is(err, ErrTruncated) {
+		t.Errorf("Core string should be truncated: %v", err)
+		return
+	}
+
+	if decodeErr.SegmentType != SegmentTypeCoreString || decodeErr.Field != "VendorsConsent" || decodeErr.Offset != 230 || decodeErr.Expected != 750 || decodeErr.Available != 106 {
+		t.Errorf("DecodeError should locate the truncated field: %+v", decodeErr)
+	}
+
+	_, err = DecodeCoreString("COxR03k*")
+	if !errors.Is(err, ErrBadBase64) {
+		t.Errorf("Core string should be bad base64: %v", err)
+	}
+
+	_, err = DecodeDisclosedVendors("elAAAAAAAWA")
+	if !errors.Is(err, ErrWrongSegmentType) {
+		t.Errorf("Publisher TC should not be decoded as disclosed vendors: %v", err)
+	}
+
+	_, err = Decode(str + "." + str)
+	if !errors.Is(err, ErrDuplicateSegment) {
+		t.Errorf("TC String should have a duplicate segment: %v", err)
+	}
+
+	_, err = Decode("elAAAAAAAWA")
+	if !errors.Is(err, ErrMissingCore) {
+		t.Errorf("TC String should miss its core string: %v", err)
+	}
+}
+
+func TestDecodeWithOptions(t *testing.T) {
+	core := "COxR03kOxR1CqBcABCENAgCMAP_AAH_AAAqIF3EXySoGY2thI2YVFxBEIYwfJxyigMgChgQIsSwNQIeFLBoGLiAAHBGYJAQAGBAEEACBAQIkHGBMCQAAgAgBiRCMQEGMCzNIBIBAggEbY0FACCVmHkHSmZCY7064O__QLuIJEFQMAkSBAIACLECIQwAQDiAAAYAlAAABAhIaAAgIWBQEeAAAACAwAAgAAABBAAACAAQAAICIAAABAAAgAiAQAAAAGgIQAACBABACRIAAAEANCAAgiCEAQg4EAo4AAA"
+
+	_, warnings, err := DecodeWithOptions(core+".IF3EXySoGY2tho2YVFzBEIYwfJxyigMgShgQIsS0NQIeFLBoGPiAAHBGYJAQAGBAkkACBAQIsHGBMCQABgAgRiRCMQEGMDzNIBIBAggkbY0FACCVmnkHS3ZCY70-6u__QA.elAAAAAAAWA", DecodeOptions{Mode: DecodeModeStrict})
+	if err != nil || len(warnings) != 0 {
+		t.Errorf("TC String should be decoded in strict mode without error nor warning: %v %v", err, warnings)
+		return
+	}
+
+	tests := []struct {
+		name     string
+		tcString string
+		err      error
+	}{
+		{"malformed segment", core + ".IF3EXySo", ErrTruncated},
+		{"unknown segment type", core + ".oAAA", ErrUnknownSegmentType},
+		{"non-zero padding", core + ".elAAAAAAAWE", ErrNonZeroPadding},
+		{"unsupported version", (&CoreString{Version: 3, Created: timeFromDeciSeconds(16431552000), LastUpdated: timeFromDeciSeconds(16431552000), ConsentLanguage: "EN", PublisherCC: "FR"}).Encode(), ErrUnsupportedVersion},
+		{"vendor id out of range", (&CoreString{Version: 2, Created: timeFromDeciSeconds(16431552000), LastUpdated: timeFromDeciSeconds(16431552000), ConsentLanguage: "EN", PublisherCC: "FR", MaxVendorId: 10, IsRangeEncoding: true, RangeEntries: []*RangeEntry{{StartVendorID: 5, EndVendorID: 20}}}).Encode(), ErrVendorIdOutOfRange},
+	}
+
+	for _, test := range tests {
+		_, _, err := DecodeWithOptions(test.tcString, DecodeOptions{Mode: DecodeModeStrict})
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s should fail in strict mode with %v: %v", test.name, test.err, err)
+		}
+
+		data, warnings, err := DecodeWithOptions(test.tcString, DecodeOptions{Mode: DecodeModeLenient})
+		if err != nil || data == nil || data.CoreString == nil {
+			t.Errorf("%s should be decoded in lenient mode: %v", test.name, err)
+			continue
+		}
+		if len(warnings) != 1 || !errors.Is(warnings[0], test.err) {
+			t.Errorf("%s should be returned as a warning in lenient mode: %v", test.name, warnings)
+		}
+	}
+
+	data, _, _ := DecodeWithOptions(core+".IF3EXySo", DecodeOptions{Mode: DecodeModeLenient})
+	if data.DisclosedVendors != nil {
+		t.Errorf("Malformed Disclosed Vendors segment should be dropped in lenient mode")
+	}
+}
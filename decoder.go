@@ -0,0 +1,152 @@
+package iabtcfv2
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+)
+
+// segmentDecoder reads the fields of a segment with bounds checking
+// Once a field doesn't fit in the remaining bits, err is set and all subsequent reads return zero values
+type segmentDecoder struct {
+	*TCEncoder
+	segmentType SegmentType
+	err         error
+}
+
+func newSegmentDecoder(segmentType SegmentType, segment string) (*segmentDecoder, error) {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return nil, &DecodeError{SegmentType: segmentType, Err: fmt.Errorf("%w: %v", ErrBadBase64, err)}
+	}
+
+	return &segmentDecoder{TCEncoder: NewTCEncoder(b), segmentType: segmentType}, nil
+}
+
+func (d *segmentDecoder) available() uint {
+	return uint(len(d.Bytes))*8 - d.Position
+}
+
+// Returns true if n bits can be read for field
+func (d *segmentDecoder) require(field string, n uint) bool {
+	if d.err != nil {
+		return false
+	}
+
+	if n > d.available() {
+		d.err = &DecodeError{
+			SegmentType: d.segmentType,
+			Field:       field,
+			Offset:      d.Position,
+			Expected:    n,
+			Available:   d.available(),
+			Err:         ErrTruncated,
+		}
+		return false
+	}
+	return true
+}
+
+func (d *segmentDecoder) readBool(field string) bool {
+	if !d.require(field, bitsBool) {
+		return false
+	}
+	return d.ReadBool()
+}
+
+func (d *segmentDecoder) readInt(field string, n uint) int {
+	if !d.require(field, n) {
+		return 0
+	}
+	return d.ReadInt(n)
+}
+
+func (d *segmentDecoder) readTime(field string) time.Time {
+	if !d.require(field, bitsTime) {
+		return time.Time{}
+	}
+	return d.ReadTime()
+}
+
+func (d *segmentDecoder) readChars(field string, n uint) string {
+	if !d.require(field, n) {
+		return ""
+	}
+	return d.ReadChars(n)
+}
+
+func (d *segmentDecoder) readBitField(field string, n uint) map[int]bool {
+	if !d.require(field, n) {
+		return nil
+	}
+	return d.ReadBitField(n)
+}
+
+func (d *segmentDecoder) readRangeEntries(field string) (int, []*RangeEntry) {
+	n := d.readInt(field, bitsNumEntries)
+	var ret = make([]*RangeEntry, 0, n)
+	for i := 0; i < n; i++ {
+		var isRange = d.readBool(field)
+		var start, end int
+		start = d.readInt(field, bitsVendorId)
+		if isRange {
+			end = d.readInt(field, bitsVendorId)
+		} else {
+			end = start
+		}
+		if d.err != nil {
+			return 0, nil
+		}
+		ret = append(ret, &RangeEntry{StartVendorID: start, EndVendorID: end})
+	}
+	return n, ret
+}
+
+func (d *segmentDecoder) readPubRestrictions(field string) (int, []*PubRestriction) {
+	n := d.readInt(field, bitsNumPubRestrictions)
+	var ret = make([]*PubRestriction, 0, n)
+	for i := 0; i < n; i++ {
+		var purposeId = d.readInt(field, bitsPubRestrictionsEntryPurposeId)
+		var restrictionType = d.readInt(field, bitsPubRestrictionsEntryRestrictionType)
+		numEntries, rangeEntries := d.readRangeEntries(field)
+		if d.err != nil {
+			return 0, nil
+		}
+		ret = append(ret, &PubRestriction{PurposeId: purposeId,
+			RestrictionType: RestrictionType(restrictionType),
+			NumEntries:      numEntries,
+			RangeEntries:    rangeEntries,
+			VendorSet:       newIDSetFromEncoding(true, nil, rangeEntries),
+		})
+	}
+	return n, ret
+}
+
+// Reads the segment type and checks it matches the decoder segment type
+func (d *segmentDecoder) readSegmentType() int {
+	segmentType := d.readInt("SegmentType", bitsSegmentType)
+	if d.err == nil && segmentType != int(d.segmentType) {
+		d.err = &DecodeError{
+			SegmentType: d.segmentType,
+			Field:       "SegmentType",
+			Err:         fmt.Errorf("%w: %d instead of %d", ErrWrongSegmentType, segmentType, d.segmentType),
+		}
+	}
+	return segmentType
+}
+
+// Returns an error if the bits left after the last field, or the unused bits of the last base64 character, are not all zero
+func (d *segmentDecoder) checkPadding(segment string) error {
+	for d.Position < uint(len(d.Bytes))*8 {
+		offset := d.Position
+		if d.ReadBool() {
+			return &DecodeError{SegmentType: d.segmentType, Field: "Padding", Offset: offset, Err: ErrNonZeroPadding}
+		}
+	}
+
+	if base64.RawURLEncoding.EncodeToString(d.Bytes) != segment {
+		return &DecodeError{SegmentType: d.segmentType, Field: "Padding", Offset: d.Position, Err: ErrNonZeroPadding}
+	}
+
+	return nil
+}
@@ -0,0 +1,251 @@
+package iabtcfv2
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TCDataDiff lists the differences between two TCData
+// Encoding details such as MaxVendorId or IsRangeEncoding aren't compared, only the ids they encode
+type TCDataDiff struct {
+	Fields          []*FieldChange          `json:"fields"`
+	IDs             []*IDsChange            `json:"ids"`
+	PubRestrictions []*PubRestrictionChange `json:"pubRestrictions"`
+}
+
+// FieldChange is a scalar field of a segment with a different value
+// Field is the name of the structure field
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// IDsChange lists the ids added to and removed from a purposes, special features or vendors field
+type IDsChange struct {
+	Field   string `json:"field"`
+	Added   []int  `json:"added,omitempty"`
+	Removed []int  `json:"removed,omitempty"`
+}
+
+// PubRestrictionChange lists the vendors added to and removed from a restriction type for a purpose
+type PubRestrictionChange struct {
+	PurposeId       int             `json:"purposeId"`
+	RestrictionType RestrictionType `json:"restrictionType"`
+	Added           []int           `json:"added,omitempty"`
+	Removed         []int           `json:"removed,omitempty"`
+}
+
+const (
+	fieldDisclosedVendors = "DisclosedVendors"
+	fieldAllowedVendors   = "AllowedVendors"
+)
+
+// Returns the differences from a to b
+// A nil TCData or a missing segment is compared as an empty one
+func Diff(a, b *TCData) *TCDataDiff {
+	d := &TCDataDiff{}
+	if a == nil {
+		a = &TCData{}
+	}
+	if b == nil {
+		b = &TCData{}
+	}
+
+	ca, cb := a.CoreString, b.CoreString
+	if ca == nil {
+		ca = &CoreString{}
+	}
+	if cb == nil {
+		cb = &CoreString{}
+	}
+	d.field("Version", ca.Version, cb.Version)
+	d.field("Created", ca.Created, cb.Created)
+	d.field("LastUpdated", ca.LastUpdated, cb.LastUpdated)
+	d.field("CmpId", ca.CmpId, cb.CmpId)
+	d.field("CmpVersion", ca.CmpVersion, cb.CmpVersion)
+	d.field("ConsentScreen", ca.ConsentScreen, cb.ConsentScreen)
+	d.field("ConsentLanguage", ca.ConsentLanguage, cb.ConsentLanguage)
+	d.field("VendorListVersion", ca.VendorListVersion, cb.VendorListVersion)
+	d.field("TcfPolicyVersion", ca.TcfPolicyVersion, cb.TcfPolicyVersion)
+	d.field("IsServiceSpecific", ca.IsServiceSpecific, cb.IsServiceSpecific)
+	d.field("UseNonStandardTexts", ca.UseNonStandardTexts, cb.UseNonStandardTexts)
+	d.field("PurposeOneTreatment", ca.PurposeOneTreatment, cb.PurposeOneTreatment)
+	d.field("PublisherCC", ca.PublisherCC, cb.PublisherCC)
+
+	d.ids(fieldSpecialFeatureOptIns, newIDSetFromEncoding(false, ca.SpecialFeatureOptIns, nil), newIDSetFromEncoding(false, cb.SpecialFeatureOptIns, nil))
+	d.ids(fieldPurposesConsent, newIDSetFromEncoding(false, ca.PurposesConsent, nil), newIDSetFromEncoding(false, cb.PurposesConsent, nil))
+	d.ids(fieldPurposesLITransparency, newIDSetFromEncoding(false, ca.PurposesLITransparency, nil), newIDSetFromEncoding(false, cb.PurposesLITransparency, nil))
+	d.ids(fieldVendorsConsent, newIDSetFromEncoding(ca.IsRangeEncoding, ca.VendorsConsent, ca.RangeEntries), newIDSetFromEncoding(cb.IsRangeEncoding, cb.VendorsConsent, cb.RangeEntries))
+	d.ids(fieldVendorsLITransparency, newIDSetFromEncoding(ca.IsRangeEncodingLI, ca.VendorsLITransparency, ca.RangeEntriesLI), newIDSetFromEncoding(cb.IsRangeEncodingLI, cb.VendorsLITransparency, cb.RangeEntriesLI))
+
+	d.ids(fieldDisclosedVendors, disclosedVendorsSet(a.DisclosedVendors), disclosedVendorsSet(b.DisclosedVendors))
+	d.ids(fieldAllowedVendors, allowedVendorsSet(a.AllowedVendors), allowedVendorsSet(b.AllowedVendors))
+
+	pa, pb := a.PublisherTC, b.PublisherTC
+	if pa == nil {
+		pa = &PublisherTC{}
+	}
+	if pb == nil {
+		pb = &PublisherTC{}
+	}
+	d.field("NumCustomPurposes", pa.NumCustomPurposes, pb.NumCustomPurposes)
+	d.ids(fieldPubPurposesConsent, newIDSetFromEncoding(false, pa.PubPurposesConsent, nil), newIDSetFromEncoding(false, pb.PubPurposesConsent, nil))
+	d.ids(fieldPubPurposesLITransparency, newIDSetFromEncoding(false, pa.PubPurposesLITransparency, nil), newIDSetFromEncoding(false, pb.PubPurposesLITransparency, nil))
+	d.ids(fieldCustomPurposesConsent, newIDSetFromEncoding(false, pa.CustomPurposesConsent, nil), newIDSetFromEncoding(false, pb.CustomPurposesConsent, nil))
+	d.ids(fieldCustomPurposesLITransparency, newIDSetFromEncoding(false, pa.CustomPurposesLITransparency, nil), newIDSetFromEncoding(false, pb.CustomPurposesLITransparency, nil))
+
+	d.pubRestrictions(ca.PubRestrictions, cb.PubRestrictions)
+
+	return d
+}
+
+// Returns true if both TCData encode the same information
+func (d *TCDataDiff) IsEmpty() bool {
+	return len(d.Fields) == 0 && len(d.IDs) == 0 && len(d.PubRestrictions) == 0
+}
+
+// Returns the differences as plain text, one line per change:
+//
+//	CmpId: 10 -> 92
+//	VendorsConsent: +2,8 -3
+//	PubRestrictions: purpose 2 requireConsent: +8
+func (d *TCDataDiff) String() string {
+	var sb strings.Builder
+	for _, f := range d.Fields {
+		fmt.Fprintf(&sb, "%s: %s -> %s\n", f.Field, formatDiffValue(f.Old), formatDiffValue(f.New))
+	}
+	for _, c := range d.IDs {
+		fmt.Fprintf(&sb, "%s:%s\n", c.Field, formatDiffIDs(c.Added, c.Removed))
+	}
+	for _, r := range d.PubRestrictions {
+		fmt.Fprintf(&sb, "%s: purpose %d %s:%s\n", fieldPubRestrictions, r.PurposeId, r.RestrictionType, formatDiffIDs(r.Added, r.Removed))
+	}
+	return sb.String()
+}
+
+func (d *TCDataDiff) field(field string, old interface{}, new interface{}) {
+	if o, ok := old.(time.Time); ok && o.Equal(new.(time.Time)) {
+		return
+	}
+	if old != new {
+		d.Fields = append(d.Fields, &FieldChange{Field: field, Old: old, New: new})
+	}
+}
+
+func (d *TCDataDiff) ids(field string, a *IDSet, b *IDSet) {
+	added, removed := diffIDSets(a, b)
+	if len(added) > 0 || len(removed) > 0 {
+		d.IDs = append(d.IDs, &IDsChange{Field: field, Added: added, Removed: removed})
+	}
+}
+
+type pubRestrictionKey struct {
+	purposeId       int
+	restrictionType RestrictionType
+}
+
+func (d *TCDataDiff) pubRestrictions(a []*PubRestriction, b []*PubRestriction) {
+	sa, sb := pubRestrictionSets(a), pubRestrictionSets(b)
+
+	var keys []pubRestrictionKey
+	for k := range sa {
+		keys = append(keys, k)
+	}
+	for k := range sb {
+		if _, ok := sa[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].purposeId != keys[j].purposeId {
+			return keys[i].purposeId < keys[j].purposeId
+		}
+		return keys[i].restrictionType < keys[j].restrictionType
+	})
+
+	for _, k := range keys {
+		added, removed := diffIDSets(sa[k], sb[k])
+		if len(added) > 0 || len(removed) > 0 {
+			d.PubRestrictions = append(d.PubRestrictions, &PubRestrictionChange{
+				PurposeId:       k.purposeId,
+				RestrictionType: k.restrictionType,
+				Added:           added,
+				Removed:         removed,
+			})
+		}
+	}
+}
+
+// Returns the vendors of each restriction type for each purpose
+func pubRestrictionSets(restrictions []*PubRestriction) map[pubRestrictionKey]*IDSet {
+	m := make(map[pubRestrictionKey]*IDSet)
+	for _, r := range restrictions {
+		k := pubRestrictionKey{purposeId: r.PurposeId, restrictionType: r.RestrictionType}
+		m[k] = m[k].Union(newIDSetFromEncoding(true, nil, r.RangeEntries))
+	}
+	return m
+}
+
+func disclosedVendorsSet(d *DisclosedVendors) *IDSet {
+	if d == nil {
+		return nil
+	}
+	return newIDSetFromEncoding(d.IsRangeEncoding, d.DisclosedVendors, d.RangeEntries)
+}
+
+func allowedVendorsSet(a *AllowedVendors) *IDSet {
+	if a == nil {
+		return nil
+	}
+	return newIDSetFromEncoding(a.IsRangeEncoding, a.AllowedVendors, a.RangeEntries)
+}
+
+// Returns the ids of b missing from a, and the ids of a missing from b
+func diffIDSets(a *IDSet, b *IDSet) (added []int, removed []int) {
+	b.Iterate(func(id int) bool {
+		if !a.Contains(id) {
+			added = append(added, id)
+		}
+		return true
+	})
+	a.Iterate(func(id int) bool {
+		if !b.Contains(id) {
+			removed = append(removed, id)
+		}
+		return true
+	})
+	return added, removed
+}
+
+func formatDiffValue(v interface{}) string {
+	switch v := v.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case string:
+		return strconv.Quote(v)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func formatDiffIDs(added []int, removed []int) string {
+	var s string
+	if len(added) > 0 {
+		s += " +" + joinIDs(added)
+	}
+	if len(removed) > 0 {
+		s += " -" + joinIDs(removed)
+	}
+	return s
+}
+
+func joinIDs(ids []int) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.Itoa(id)
+	}
+	return strings.Join(s, ",")
+}
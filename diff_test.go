@@ -0,0 +1,63 @@
+package iabtcfv2
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a, _ := Decode("CPTZZYAPTZZYABcABCENAgCMAPzAAEPAAAqIDaQBQAMgAgABqAR0A2gDaQAwAMgAgANoAAA.IDaQBQAMgAgABqAR0A2g.QDaQAgAMwAgADUA.eEAAAAAAAUA")
+	b, _ := Decode(a.ToTCString())
+
+	if d := Diff(a, b); !d.IsEmpty() {
+		t.Errorf("Same TC Strings should have no differences: %s", d)
+	}
+
+	b.CoreString.CmpId = 10
+	b.CoreString.ConsentLanguage = "FR"
+	b.CoreString.LastUpdated = timeFromDeciSeconds(16431552010)
+	b.CoreString.PurposesConsent[7] = true
+	delete(b.CoreString.PurposesConsent, 1)
+	b.CoreString.SetVendorsConsent(25, 32, 53, 285, 755)
+	b.CoreString.PubRestrictions = []*PubRestriction{
+		{PurposeId: 2, RestrictionType: RestrictionTypeRequireConsent, NumEntries: 1, RangeEntries: []*RangeEntry{{StartVendorID: 8, EndVendorID: 10}}},
+	}
+	b.DisclosedVendors = nil
+	b.PublisherTC.CustomPurposesLITransparency = map[int]bool{2: true}
+
+	d := Diff(a, b)
+	expected := `LastUpdated: 2022-01-26T00:00:00Z -> 2022-01-26T00:00:01Z
+CmpId: 92 -> 10
+ConsentLanguage: "EN" -> "FR"
+PurposesConsent: +7 -1
+VendorsConsent: +755 -436
+DisclosedVendors: -25,32,53,285,436
+CustomPurposesLITransparency: +2
+PubRestrictions: purpose 2 requireConsent: +8,9,10
+`
+	if d.String() != expected {
+		t.Errorf("Unexpected diff:\n%s", d)
+	}
+
+	if d.Fields[1].Old != 92 || d.Fields[1].New != 10 {
+		t.Errorf("Field change should hold the old and new values: %+v", d.Fields[1])
+	}
+
+	// Range and bit field encodings of the same vendors are equal
+	b, _ = Decode(a.ToTCString())
+	b.CoreString.OptimizeVendorEncoding()
+	b.CoreString.IsRangeEncoding = false
+	b.CoreString.VendorsConsent = map[int]bool{25: true, 32: true, 53: true, 285: true, 436: true}
+	if d := Diff(a, b); !d.IsEmpty() {
+		t.Errorf("Vendor encoding should not be compared: %s", d)
+	}
+
+	if d := Diff(nil, nil); !d.IsEmpty() {
+		t.Errorf("Nil TCData should have no differences: %s", d)
+	}
+	if d := Diff(nil, a); d.IsEmpty() || d.Fields[0].Field != "Version" {
+		t.Errorf("Nil TCData should be compared as empty: %s", d)
+	}
+	if d := Diff(a, nil); d.IsEmpty() {
+		t.Errorf("Nil TCData should be compared as empty: %s", d)
+	}
+}
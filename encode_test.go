@@ -266,6 +266,23 @@ func TestEncodeDisclosedVendors(t *testing.T) {
 	}
 }
 
+func TestEncodeAllowedVendors(t *testing.T) {
+	str := "QAEmCA"
+	segment := &AllowedVendors{
+		SegmentType: 2,
+		AllowedVendors: map[int]bool{
+			2: true,
+			3: true,
+			9: true,
+		},
+	}
+
+	result := segment.Encode()
+	if result != str {
+		t.Errorf("Encode() should produce the same string: in = %s, out = %s", str, result)
+	}
+}
+
 func TestEncodePublisherTC(t *testing.T) {
 	str := "eEAAAAAAAUA"
 	segment := &PublisherTC{
@@ -292,3 +309,49 @@ func TestEncodePublisherTC(t *testing.T) {
 func timeFromDeciSeconds(deciseconds int64) time.Time {
 	return time.Unix(deciseconds/10, (deciseconds%10)*int64(time.Millisecond*100)).UTC()
 }
+
+func TestFibonacciInt(t *testing.T) {
+	codes := map[int]string{1: "11", 2: "011", 3: "0011", 4: "1011", 5: "00011", 12: "101011"}
+	for v, code := range codes {
+		e := NewTCEncoderFromSize(len(code) + 2)
+		e.WriteFibonacciInt(v)
+		if FibonacciIntBitSize(v) != len(code) || e.Position != uint(len(code)) {
+			t.Errorf("%d should be coded on %d bits", v, len(code))
+			continue
+		}
+
+		e.Position = 0
+		for i := range code {
+			if e.ReadBool() != (code[i] == '1') {
+				t.Errorf("%d should be coded as %s", v, code)
+				break
+			}
+		}
+
+		e.Position = 0
+		if e.ReadFibonacciInt() != v {
+			t.Errorf("%s should be read as %d", code, v)
+		}
+	}
+
+	// 0 bits followed by a terminating code, past the longest code
+	e := NewTCEncoderFromSize(MaxFibonacciIntBits + 2)
+	e.Position = MaxFibonacciIntBits
+	e.WriteBool(true)
+	e.WriteBool(true)
+	e.Position = 0
+	if v := e.ReadFibonacciInt(); v != 0 || e.Position != MaxFibonacciIntBits {
+		t.Errorf("Code longer than %d bits should be read as 0: %d", MaxFibonacciIntBits, v)
+	}
+}
+
+func TestNBitField(t *testing.T) {
+	e := NewTCEncoderFromSize(2 * 5)
+	e.WriteNBitField([]int{0, 1, 2, 3}, 5, 2)
+
+	e.Position = 0
+	values := e.ReadNBitField(5, 2)
+	if len(values) != 5 || values[0] != 0 || values[1] != 1 || values[2] != 2 || values[3] != 3 || values[4] != 0 {
+		t.Errorf("Fields should be read as written: %v", values)
+	}
+}
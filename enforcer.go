@@ -0,0 +1,200 @@
+package iabtcfv2
+
+import (
+	"sort"
+
+	"github.com/SirDataFR/iabtcfv2/gvl"
+)
+
+// Enforcer checks the signals of a TC String against the purposes
+// each vendor declared in the Global Vendor List
+type Enforcer struct {
+	TCData     *TCData
+	VendorList *gvl.VendorList
+	// Overrides the rules of the TcfPolicyVersion of TCData when not nil
+	PolicyRules *PolicyRules
+}
+
+func NewEnforcer(t *TCData, vl *gvl.VendorList) *Enforcer {
+	return &Enforcer{TCData: t, VendorList: vl}
+}
+
+// Returns the legal basis vendor id can rely on to process personal data for purpose id
+// in accordance with its declarations and publisher restrictions,
+// or LegalBasisNone if processing is not allowed
+func (e *Enforcer) LegalBasis(vendorId int, purposeId int) LegalBasis {
+	return e.legalBasis(nil, vendorId, purposeId)
+}
+
+// Returns the Decision of LegalBasis
+func (e *Enforcer) ExplainLegalBasis(vendorId int, purposeId int) *Decision {
+	d := &Decision{}
+	e.legalBasis(d, vendorId, purposeId)
+	return d
+}
+
+// Returns true if vendor id can process personal data for purpose id
+func (e *Enforcer) IsVendorAllowedForPurpose(vendorId int, purposeId int) bool {
+	return e.LegalBasis(vendorId, purposeId) != LegalBasisNone
+}
+
+// Returns true if vendor id can process personal data for all purpose ids
+func (e *Enforcer) IsVendorAllowedForPurposes(vendorId int, purposeIds ...int) bool {
+	for _, p := range purposeIds {
+		if !e.IsVendorAllowedForPurpose(vendorId, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns true if vendor id declared special purpose id
+// Special purposes rely on legitimate interest and users have no right to object to them
+func (e *Enforcer) CanVendorUseSpecialPurpose(vendorId int, specialPurposeId int) bool {
+	v := e.vendor(vendorId)
+	if v == nil {
+		return false
+	}
+	return v.HasSpecialPurpose(specialPurposeId)
+}
+
+// Returns the features vendor id declared, or nil if it is not in the vendor list
+// Features are disclosure-only and don't require any signal from the user
+func (e *Enforcer) VendorFeatures(vendorId int) []int {
+	v := e.vendor(vendorId)
+	if v == nil {
+		return nil
+	}
+	return sortedIDs(v.Features)
+}
+
+// VendorPermissions lists what a vendor can do in accordance with the TC String and its declarations
+// Purposes maps each allowed purpose to its legal basis
+type VendorPermissions struct {
+	VendorId        int                `json:"vendorId"`
+	Purposes        map[int]LegalBasis `json:"purposes"`
+	SpecialFeatures []int              `json:"specialFeatures"`
+	SpecialPurposes []int              `json:"specialPurposes"`
+	Features        []int              `json:"features"`
+}
+
+// Returns the permissions of vendor id, or nil if it is not in the vendor list
+// Special features are the declared ones the user opted in, special purposes and features are all the declared ones
+func (e *Enforcer) VendorPermissions(vendorId int) *VendorPermissions {
+	v := e.vendor(vendorId)
+	if v == nil {
+		return nil
+	}
+
+	p := &VendorPermissions{
+		VendorId:        vendorId,
+		Purposes:        make(map[int]LegalBasis),
+		SpecialPurposes: sortedIDs(v.SpecialPurposes),
+		Features:        sortedIDs(v.Features),
+	}
+	for _, ids := range [][]int{v.Purposes, v.LegIntPurposes} {
+		for _, id := range ids {
+			if legalBasis := e.LegalBasis(vendorId, id); legalBasis != LegalBasisNone {
+				p.Purposes[id] = legalBasis
+			}
+		}
+	}
+	for _, id := range sortedIDs(v.SpecialFeatures) {
+		if e.TCData.CoreString.IsSpecialFeatureAllowed(id) {
+			p.SpecialFeatures = append(p.SpecialFeatures, id)
+		}
+	}
+	return p
+}
+
+func (e *Enforcer) legalBasis(d *Decision, vendorId int, purposeId int) LegalBasis {
+	v := e.vendor(vendorId)
+	if !d.check(fieldVendorListVendors, vendorId, v != nil) {
+		d.deny()
+		return LegalBasisNone
+	}
+
+	c := e.TCData.CoreString
+	declaresConsent := v.HasPurpose(purposeId)
+	declaresLI := v.HasLegIntPurpose(purposeId)
+	if !declaresConsent && !declaresLI {
+		d.check(fieldVendorPurposes, purposeId, false)
+		d.check(fieldVendorLegIntPurposes, purposeId, false)
+		d.deny()
+		return LegalBasisNone
+	}
+
+	legalBasis := LegalBasisConsent
+	if !declaresConsent {
+		legalBasis = LegalBasisLegitimateInterest
+	}
+
+	for _, r := range c.GetPubRestrictionsForPurpose(purposeId) {
+		if !r.IsVendorIncluded(vendorId) {
+			continue
+		}
+		if !d.checkRestriction(r, vendorId, r.RestrictionType != RestrictionTypeNotAllowed) {
+			d.deny()
+			return LegalBasisNone
+		}
+
+		// A publisher can only change the legal basis of a purpose the vendor declared as flexible
+		required := legalBasis
+		switch r.RestrictionType {
+		case RestrictionTypeRequireConsent:
+			required = LegalBasisConsent
+		case RestrictionTypeRequireLI:
+			required = LegalBasisLegitimateInterest
+		}
+		if required != legalBasis {
+			if !d.check(fieldVendorFlexiblePurposes, purposeId, v.IsFlexiblePurpose(purposeId)) {
+				d.deny()
+				return LegalBasisNone
+			}
+			legalBasis = required
+		}
+	}
+
+	if !e.checkSignals(d, vendorId, purposeId, legalBasis) {
+		d.deny()
+		return LegalBasisNone
+	}
+
+	d.use(purposeId, legalBasis)
+	d.allow(legalBasis)
+	return legalBasis
+}
+
+func (e *Enforcer) vendor(id int) *gvl.Vendor {
+	if e.TCData == nil || e.TCData.CoreString == nil || e.VendorList == nil {
+		return nil
+	}
+
+	v := e.VendorList.Vendor(id)
+	if v == nil || v.IsDeleted() {
+		return nil
+	}
+	return v
+}
+
+func (e *Enforcer) checkSignals(d *Decision, vendorId int, purposeId int, legalBasis LegalBasis) bool {
+	c := e.TCData.CoreString
+	if legalBasis == LegalBasisConsent {
+		return d.check(fieldVendorsConsent, vendorId, c.IsVendorAllowed(vendorId)) &&
+			d.check(fieldPurposesConsent, purposeId, c.IsPurposeAllowed(purposeId))
+	}
+
+	rules := e.PolicyRules
+	if rules == nil {
+		rules = policyRulesForVersion(c.TcfPolicyVersion)
+	}
+	return d.check(fieldVendorsLITransparency, vendorId, c.IsVendorLIAllowed(vendorId)) &&
+		d.check(fieldPolicyNoLegitimateInterestPurposes, purposeId, rules.AllowsLegitimateInterest(purposeId)) &&
+		d.check(fieldPurposesLITransparency, purposeId, c.IsPurposeLIAllowed(purposeId))
+}
+
+func sortedIDs(ids []int) []int {
+	sorted := append([]int{}, ids...)
+	sort.Ints(sorted)
+	return sorted
+}
@@ -0,0 +1,140 @@
+package iabtcfv2
+
+import (
+	"testing"
+
+	"github.com/SirDataFR/iabtcfv2/gvl"
+)
+
+func newTestEnforcer(t *testing.T, restrictions ...*PubRestriction) *Enforcer {
+	vl, err := gvl.LoadFile("gvl/testdata/vendor-list-v3.json")
+	if err != nil {
+		t.Fatalf("Vendor list should be loaded without error: %s", err)
+	}
+
+	data := &TCData{
+		CoreString: &CoreString{
+			Version:                2,
+			TcfPolicyVersion:       4,
+			VendorListVersion:      150,
+			PurposesConsent:        map[int]bool{1: true, 2: true, 3: true, 4: true},
+			PurposesLITransparency: map[int]bool{2: true, 7: true},
+			VendorsConsent:         map[int]bool{1: true, 2: true, 3: true, 4: true},
+			VendorsLITransparency:  map[int]bool{1: true, 2: true},
+			NumPubRestrictions:     len(restrictions),
+			PubRestrictions:        restrictions,
+		},
+	}
+
+	return NewEnforcer(data, vl)
+}
+
+func TestEnforcerLegalBasis(t *testing.T) {
+	e := newTestEnforcer(t)
+
+	if e.LegalBasis(1, 3) != LegalBasisConsent {
+		t.Errorf("Vendor 1 should process purpose 3 under consent")
+	}
+
+	if e.LegalBasis(1, 2) != LegalBasisLegitimateInterest {
+		t.Errorf("Vendor 1 should process purpose 2 under legitimate interest")
+	}
+
+	if e.LegalBasis(2, 8) != LegalBasisNone {
+		t.Errorf("Vendor 2 should not process purpose 8 without purpose legitimate interest")
+	}
+
+	if e.LegalBasis(2, 3) != LegalBasisNone {
+		t.Errorf("Vendor 2 should not process purpose 3 because it did not declare it")
+	}
+
+	if e.LegalBasis(4, 1) != LegalBasisNone {
+		t.Errorf("Vendor 4 should not process purpose 1 because it is deleted")
+	}
+
+	if e.LegalBasis(999, 1) != LegalBasisNone {
+		t.Errorf("Vendor 999 should not process purpose 1 because it is not in the vendor list")
+	}
+
+	if !e.IsVendorAllowedForPurposes(3, 1, 2, 3, 4) {
+		t.Errorf("Vendor 3 should be allowed for purposes 1, 2, 3 and 4")
+	}
+
+	if e.IsVendorAllowedForPurposes(3, 1, 7) {
+		t.Errorf("Vendor 3 should not be allowed for purpose 7 without purpose consent")
+	}
+}
+
+func TestEnforcerPubRestrictions(t *testing.T) {
+	e := newTestEnforcer(t,
+		&PubRestriction{PurposeId: 2, RestrictionType: RestrictionTypeRequireConsent, NumEntries: 1, RangeEntries: []*RangeEntry{{StartVendorID: 1, EndVendorID: 1}}},
+		&PubRestriction{PurposeId: 7, RestrictionType: RestrictionTypeRequireConsent, NumEntries: 1, RangeEntries: []*RangeEntry{{StartVendorID: 2, EndVendorID: 2}}},
+		&PubRestriction{PurposeId: 3, RestrictionType: RestrictionTypeNotAllowed, NumEntries: 1, RangeEntries: []*RangeEntry{{StartVendorID: 1, EndVendorID: 3}}},
+	)
+
+	if e.LegalBasis(1, 2) != LegalBasisConsent {
+		t.Errorf("Vendor 1 should process flexible purpose 2 under consent as required by publisher")
+	}
+
+	if e.LegalBasis(2, 7) != LegalBasisNone {
+		t.Errorf("Vendor 2 should not process purpose 7 because it is not flexible and publisher requires consent")
+	}
+
+	if e.LegalBasis(1, 3) != LegalBasisNone || e.LegalBasis(3, 3) != LegalBasisNone {
+		t.Errorf("Vendors 1 and 3 should not process purpose 3 because publisher does not allow it")
+	}
+}
+
+func TestEnforcerSpecialPurposes(t *testing.T) {
+	e := newTestEnforcer(t)
+
+	if !e.CanVendorUseSpecialPurpose(1, 2) {
+		t.Errorf("Vendor 1 should use special purpose 2")
+	}
+
+	if e.CanVendorUseSpecialPurpose(2, 2) {
+		t.Errorf("Vendor 2 should not use special purpose 2 because it did not declare it")
+	}
+
+	if e.CanVendorUseSpecialPurpose(4, 1) {
+		t.Errorf("Vendor 4 should not use special purpose 1 because it is deleted")
+	}
+}
+
+func TestEnforcerVendorPermissions(t *testing.T) {
+	e := newTestEnforcer(t)
+	e.TCData.CoreString.SpecialFeatureOptIns = map[int]bool{1: true}
+
+	if f := e.VendorFeatures(3); len(f) != 1 || f[0] != 2 {
+		t.Errorf("Vendor 3 should declare feature 2: %v", f)
+	}
+
+	if e.VendorFeatures(4) != nil || e.VendorPermissions(4) != nil {
+		t.Errorf("Vendor 4 should have no features nor permissions because it is deleted")
+	}
+
+	p := e.VendorPermissions(3)
+	if p == nil {
+		t.Fatalf("Vendor 3 should have permissions")
+	}
+	expected := map[int]LegalBasis{1: LegalBasisConsent, 2: LegalBasisConsent, 3: LegalBasisConsent, 4: LegalBasisConsent}
+	if len(p.Purposes) != len(expected) {
+		t.Errorf("Unexpected purposes of vendor 3: %v", p.Purposes)
+	}
+	for id, legalBasis := range expected {
+		if p.Purposes[id] != legalBasis {
+			t.Errorf("Vendor 3 should process purpose %d under %s: %v", id, legalBasis, p.Purposes)
+		}
+	}
+	if len(p.SpecialFeatures) != 1 || p.SpecialFeatures[0] != 1 {
+		t.Errorf("Vendor 3 should only use opted in special feature 1: %v", p.SpecialFeatures)
+	}
+	if len(p.SpecialPurposes) != 0 || len(p.Features) != 1 {
+		t.Errorf("Unexpected special purposes or features of vendor 3: %+v", p)
+	}
+
+	p = e.VendorPermissions(1)
+	if p.Purposes[2] != LegalBasisLegitimateInterest || len(p.SpecialPurposes) != 2 || len(p.SpecialFeatures) != 1 {
+		t.Errorf("Unexpected permissions of vendor 1: %+v", p)
+	}
+}
@@ -0,0 +1,63 @@
+package iabtcfv2
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrTruncated        = errors.New("truncated")
+	ErrBadBase64        = errors.New("bad base64")
+	ErrWrongSegmentType = errors.New("wrong segment type")
+	ErrDuplicateSegment = errors.New("duplicate segment")
+	ErrMissingCore      = errors.New("missing core string")
+
+	ErrUnknownSegmentType = errors.New("unknown segment type")
+	ErrNonZeroPadding     = errors.New("non-zero padding bits")
+	ErrUnsupportedVersion = errors.New("unsupported version")
+	ErrVendorIdOutOfRange = errors.New("vendor id above max vendor id")
+
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+// DecodeError describes why a TC String or one of its segments couldn't be decoded
+// Err is one of the ErrXxx sentinel errors, possibly wrapped with details
+type DecodeError struct {
+	SegmentType SegmentType
+	Field       string
+	Offset      uint
+	Expected    uint
+	Available   uint
+	Err         error
+}
+
+func (e *DecodeError) Error() string {
+	msg := e.SegmentType.String()
+	if e.Field != "" {
+		msg += ": " + e.Field
+		if errors.Is(e.Err, ErrTruncated) {
+			msg += fmt.Sprintf(" at bit %d: expected %d bits, %d available", e.Offset, e.Expected, e.Available)
+		}
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func (s SegmentType) String() string {
+	switch s {
+	case SegmentTypeCoreString:
+		return "core string"
+	case SegmentTypeDisclosedVendors:
+		return "disclosed vendors"
+	case SegmentTypeAllowedVendors:
+		return "allowed vendors"
+	case SegmentTypePublisherTC:
+		return "publisher TC"
+	case SegmentTypeUndefined:
+		return "tc string"
+	}
+	return fmt.Sprintf("segment type %d", int(s))
+}
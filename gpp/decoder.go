@@ -0,0 +1,269 @@
+package gpp
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SirDataFR/iabtcfv2"
+)
+
+const (
+	bitsBool       = 1
+	bitsType       = 6
+	bitsVersion    = 6
+	bitsNumEntries = 12
+	bitsIsRange    = bitsBool
+	base64Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	bitsBase64Char = 6
+
+	bitsTime                                = 36
+	bitsChar                                = 6
+	bitsSegmentType                         = 3
+	bitsMaxVendorId                         = 16
+	bitsVendorId                            = 16
+	bitsNumPubRestrictions                  = 12
+	bitsPubRestrictionsEntryPurposeId       = 6
+	bitsPubRestrictionsEntryRestrictionType = 2
+)
+
+// decoder reads the fields of a GPP header or section with bounds checking
+// Once a field doesn't fit in the remaining bits, err is set and all subsequent reads return zero values
+type decoder struct {
+	*iabtcfv2.TCEncoder
+	section string
+	err     error
+}
+
+// GPP strings are base64 url encoded 6 bits at a time, without the byte alignment required by encoding/base64
+func newDecoder(section string, s string) (*decoder, error) {
+	b := make([]byte, (len(s)*bitsBase64Char+7)/8)
+	e := &iabtcfv2.Bits{Bytes: b}
+	for i := 0; i < len(s); i++ {
+		v := strings.IndexByte(base64Alphabet, s[i])
+		if v < 0 {
+			return nil, &DecodeError{Section: section, Err: fmt.Errorf("%w: illegal character at input byte %d", iabtcfv2.ErrBadBase64, i)}
+		}
+		e.WriteInt(v, bitsBase64Char)
+	}
+
+	return &decoder{TCEncoder: iabtcfv2.NewTCEncoder(b), section: section}, nil
+}
+
+// Encodes bytes as base64 url without padding, as GPP strings are
+func encodeBase64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (d *decoder) available() uint {
+	return uint(len(d.Bytes))*8 - d.Position
+}
+
+// Returns true if n bits can be read for field
+func (d *decoder) require(field string, n uint) bool {
+	if d.err != nil {
+		return false
+	}
+
+	if n > d.available() {
+		d.err = &DecodeError{
+			Section:   d.section,
+			Field:     field,
+			Offset:    d.Position,
+			Expected:  n,
+			Available: d.available(),
+			Err:       iabtcfv2.ErrTruncated,
+		}
+		return false
+	}
+	return true
+}
+
+func (d *decoder) readBool(field string) bool {
+	if !d.require(field, bitsBool) {
+		return false
+	}
+	return d.ReadBool()
+}
+
+func (d *decoder) readInt(field string, n uint) int {
+	if !d.require(field, n) {
+		return 0
+	}
+	return d.ReadInt(n)
+}
+
+// Reads a Fibonacci coded integer, checking first that its terminating bits are available
+// and that it is at most MaxFibonacciIntBits long
+func (d *decoder) readFibonacci(field string) int {
+	if d.err != nil {
+		return 0
+	}
+
+	n := uint(0)
+	previous := false
+	for p := d.Position; p < uint(len(d.Bytes))*8; p++ {
+		n++
+		if n > iabtcfv2.MaxFibonacciIntBits {
+			d.err = &DecodeError{Section: d.section, Field: field, Offset: d.Position, Err: fmt.Errorf("%w: fibonacci integer longer than %d bits", iabtcfv2.ErrInvalidValue, iabtcfv2.MaxFibonacciIntBits)}
+			return 0
+		}
+		bit := d.Bytes[p/8]&(0x80>>(p%8)) != 0
+		if bit && previous {
+			return d.ReadFibonacciInt()
+		}
+		previous = bit
+	}
+
+	d.require(field, n+1)
+	return 0
+}
+
+// Reads n fields of bitsPerField bits
+func (d *decoder) readNBitField(field string, n int, bitsPerField uint) []int {
+	if !d.require(field, uint(n)*bitsPerField) {
+		return nil
+	}
+	return d.ReadNBitField(n, bitsPerField)
+}
+
+// Reads ids as range entries coded with Fibonacci integers
+// Each entry is either an id or a range, whose start is an offset from the previous id, and end an offset from its start
+// Ids are strictly increasing, so checking that they don't exceed maxId before expanding a range bounds their number
+func (d *decoder) readFibonacciRange(field string, maxId int) []int {
+	n := d.readInt(field, bitsNumEntries)
+	var ids []int
+	last := 0
+	for i := 0; i < n; i++ {
+		isRange := d.readBool(field)
+		start := last + d.readFibonacci(field)
+		end := start
+		if isRange {
+			end = start + d.readFibonacci(field)
+		}
+		if d.err == nil && end > maxId {
+			d.err = &DecodeError{Section: d.section, Field: field, Offset: d.Position, Err: fmt.Errorf("%w: id %d above %d", iabtcfv2.ErrInvalidValue, end, maxId)}
+		}
+		if d.err != nil {
+			return nil
+		}
+		for id := start; id <= end; id++ {
+			ids = append(ids, id)
+		}
+		last = end
+	}
+	return ids
+}
+
+// Returns sorted ids as range entries, with consecutive ids coalesced
+// Ids below 1 and duplicates are left out, as their offset from the previous id would be 0 or negative
+func fibonacciRanges(ids []int) [][2]int {
+	var ranges [][2]int
+	for _, id := range ids {
+		n := len(ranges)
+		if id < 1 || n > 0 && id <= ranges[n-1][1] {
+			continue
+		}
+		if n > 0 && ranges[n-1][1]+1 == id {
+			ranges[n-1][1] = id
+		} else {
+			ranges = append(ranges, [2]int{id, id})
+		}
+	}
+	return ranges
+}
+
+func fibonacciRangeBitSize(ranges [][2]int) int {
+	bitSize := bitsNumEntries
+	last := 0
+	for _, r := range ranges {
+		bitSize += bitsIsRange + iabtcfv2.FibonacciIntBitSize(r[0]-last)
+		if r[1] > r[0] {
+			bitSize += iabtcfv2.FibonacciIntBitSize(r[1] - r[0])
+		}
+		last = r[1]
+	}
+	return bitSize
+}
+
+func writeFibonacciRange(e *iabtcfv2.TCEncoder, ranges [][2]int) {
+	e.WriteInt(len(ranges), bitsNumEntries)
+	last := 0
+	for _, r := range ranges {
+		e.WriteBool(r[1] > r[0])
+		e.WriteFibonacciInt(r[0] - last)
+		if r[1] > r[0] {
+			e.WriteFibonacciInt(r[1] - r[0])
+		}
+		last = r[1]
+	}
+}
+
+func (d *decoder) readTime(field string) time.Time {
+	if !d.require(field, bitsTime) {
+		return time.Time{}
+	}
+	return d.ReadTime()
+}
+
+func (d *decoder) readChars(field string, n uint) string {
+	if !d.require(field, n) {
+		return ""
+	}
+	return d.ReadChars(n)
+}
+
+func (d *decoder) readBitField(field string, n uint) map[int]bool {
+	if !d.require(field, n) {
+		return nil
+	}
+	return d.ReadBitField(n)
+}
+
+// Reads range entries with ReadRangeEntries once they are known to fit in the remaining bits
+func (d *decoder) readRangeEntries(field string) (int, []*iabtcfv2.RangeEntry) {
+	position := d.Position
+	d.skipRangeEntries(field)
+	if d.err != nil {
+		return 0, nil
+	}
+
+	d.Position = position
+	return d.ReadRangeEntries()
+}
+
+// Reads publisher restrictions with ReadPubRestrictions once they are known to fit in the remaining bits
+func (d *decoder) readPubRestrictions(field string) (int, []*iabtcfv2.PubRestriction) {
+	position := d.Position
+	n := d.readInt(field, bitsNumPubRestrictions)
+	for i := 0; i < n; i++ {
+		d.readInt(field, bitsPubRestrictionsEntryPurposeId+bitsPubRestrictionsEntryRestrictionType)
+		d.skipRangeEntries(field)
+	}
+	if d.err != nil {
+		return 0, nil
+	}
+
+	d.Position = position
+	return d.ReadPubRestrictions()
+}
+
+func (d *decoder) skipRangeEntries(field string) {
+	n := d.readInt(field, bitsNumEntries)
+	for i := 0; i < n; i++ {
+		if d.readBool(field) {
+			d.readInt(field, bitsVendorId)
+		}
+		d.readInt(field, bitsVendorId)
+	}
+}
+
+// Reads the segment type of a subsection and checks it is segmentType
+func (d *decoder) readSegmentType(segmentType int) int {
+	v := d.readInt("SegmentType", bitsSegmentType)
+	if d.err == nil && v != segmentType {
+		d.err = &DecodeError{Section: d.section, Field: "SegmentType", Err: fmt.Errorf("%w: %d instead of %d", iabtcfv2.ErrWrongSegmentType, v, segmentType)}
+	}
+	return v
+}
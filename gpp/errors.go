@@ -0,0 +1,41 @@
+package gpp
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/SirDataFR/iabtcfv2"
+)
+
+var (
+	ErrWrongHeaderType = errors.New("wrong header type")
+	ErrSectionCount    = errors.New("number of sections doesn't match header")
+	ErrMissingSection  = errors.New("missing section")
+	ErrUnknownSection  = errors.New("unknown section")
+)
+
+// DecodeError describes why a GPP String or one of its sections couldn't be decoded
+// Err is one of the ErrXxx sentinel errors of this package or of iabtcfv2, possibly wrapped with details
+type DecodeError struct {
+	Section   string
+	Field     string
+	Offset    uint
+	Expected  uint
+	Available uint
+	Err       error
+}
+
+func (e *DecodeError) Error() string {
+	msg := "gpp " + e.Section
+	if e.Field != "" {
+		msg += ": " + e.Field
+		if errors.Is(e.Err, iabtcfv2.ErrTruncated) {
+			msg += fmt.Sprintf(" at bit %d: expected %d bits, %d available", e.Offset, e.Expected, e.Available)
+		}
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,214 @@
+// Package gpp reads and writes IAB Global Privacy Platform (GPP) strings.
+//
+// A GPP String is a header followed by "~" separated sections, each section being
+// the privacy string of a jurisdiction. Sections are kept as raw strings and decoded on access.
+package gpp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/SirDataFR/iabtcfv2"
+)
+
+const (
+	HeaderType    = 3
+	HeaderVersion = 1
+)
+
+const (
+	SectionIdTCFEUv2 = 2
+	SectionIdTCFCAv1 = 5
+	SectionIdUSPv1   = 6
+	SectionIdUSNat   = 7
+	SectionIdUSCA    = 8
+	SectionIdUSVA    = 9
+	SectionIdUSCO    = 10
+	SectionIdUSUT    = 11
+	SectionIdUSCT    = 12
+)
+
+// Highest section id accepted in a header, well above the ids assigned so far
+const maxSectionId = 1000
+
+var sectionNames = map[int]string{
+	SectionIdTCFEUv2: "tcfeuv2",
+	SectionIdTCFCAv1: "tcfcav1",
+	SectionIdUSPv1:   "uspv1",
+	SectionIdUSNat:   "usnat",
+	SectionIdUSCA:    "usca",
+	SectionIdUSVA:    "usva",
+	SectionIdUSCO:    "usco",
+	SectionIdUSUT:    "usut",
+	SectionIdUSCT:    "usct",
+}
+
+// Returns the API prefix of section id, such as "tcfeuv2", or "section N" for unknown sections
+func SectionName(id int) string {
+	if name, ok := sectionNames[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("section %d", id)
+}
+
+type GPPData struct {
+	Header   *Header
+	Sections []*Section
+}
+
+type Header struct {
+	Type       int
+	Version    int
+	SectionIds []int
+}
+
+// Section is the raw value of a section, in the order of the header section ids
+type Section struct {
+	Id    int
+	Value string
+}
+
+// Decodes a GPP String and returns it as a GPPData structure
+// Sections are not decoded, see the accessors such as TCFEUv2
+func Decode(gppString string) (g *GPPData, err error) {
+	values := strings.Split(gppString, "~")
+
+	h, err := DecodeHeader(values[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values)-1 != len(h.SectionIds) {
+		return nil, &DecodeError{Section: "header", Field: "SectionIds", Err: fmt.Errorf("%w: %d sections for %d ids", ErrSectionCount, len(values)-1, len(h.SectionIds))}
+	}
+
+	g = &GPPData{Header: h}
+	for i, id := range h.SectionIds {
+		g.Sections = append(g.Sections, &Section{Id: id, Value: values[i+1]})
+	}
+
+	return g, nil
+}
+
+// Decodes a GPP header and returns it as a Header structure
+func DecodeHeader(header string) (h *Header, err error) {
+	d, err := newDecoder("header", header)
+	if err != nil {
+		return nil, err
+	}
+
+	h = &Header{}
+	h.Type = d.readInt("Type", bitsType)
+	h.Version = d.readInt("Version", bitsVersion)
+	h.SectionIds = d.readFibonacciRange("SectionIds", maxSectionId)
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	if h.Type != HeaderType {
+		return nil, &DecodeError{Section: "header", Field: "Type", Err: fmt.Errorf("%w: %d", ErrWrongHeaderType, h.Type)}
+	}
+	if h.Version != HeaderVersion {
+		return nil, &DecodeError{Section: "header", Field: "Version", Err: fmt.Errorf("%w: %d", iabtcfv2.ErrUnsupportedVersion, h.Version)}
+	}
+
+	return h, nil
+}
+
+// Returns the section ids of the GPP String, as in the gpp_sid field of OpenRTB
+func (g *GPPData) SectionIds() []int {
+	ids := make([]int, 0, len(g.Sections))
+	for _, s := range g.Sections {
+		ids = append(ids, s.Id)
+	}
+	return ids
+}
+
+// Returns section id, or nil if the GPP String doesn't contain it
+func (g *GPPData) Section(id int) *Section {
+	for _, s := range g.Sections {
+		if s.Id == id {
+			return s
+		}
+	}
+	return nil
+}
+
+// Sets the raw value of section id, adding the section if needed
+func (g *GPPData) SetSection(id int, value string) {
+	if s := g.Section(id); s != nil {
+		s.Value = value
+		return
+	}
+
+	g.Sections = append(g.Sections, &Section{Id: id, Value: value})
+	sort.Slice(g.Sections, func(i, j int) bool { return g.Sections[i].Id < g.Sections[j].Id })
+}
+
+// Removes section id from the GPP String
+func (g *GPPData) RemoveSection(id int) {
+	for i, s := range g.Sections {
+		if s.Id == id {
+			g.Sections = append(g.Sections[:i], g.Sections[i+1:]...)
+			return
+		}
+	}
+}
+
+// Decodes the tcfeuv2 section as a TC String
+func (g *GPPData) TCFEUv2() (*iabtcfv2.TCData, error) {
+	s := g.Section(SectionIdTCFEUv2)
+	if s == nil {
+		return nil, &DecodeError{Section: SectionName(SectionIdTCFEUv2), Err: ErrMissingSection}
+	}
+	return iabtcfv2.Decode(s.Value)
+}
+
+// Sets the tcfeuv2 section to the TC String of t
+func (g *GPPData) SetTCFEUv2(t *iabtcfv2.TCData) {
+	g.SetSection(SectionIdTCFEUv2, t.ToTCString())
+}
+
+// Returns structure as a GPP String, with a header listing the section ids in ascending order
+// Sections with an id below 1, or with the id of a previous section, can't be listed in the header and are left out
+func (g *GPPData) Encode() string {
+	sort.SliceStable(g.Sections, func(i, j int) bool { return g.Sections[i].Id < g.Sections[j].Id })
+
+	var ids []int
+	var values []string
+	for _, s := range g.Sections {
+		if s.Id < 1 || len(ids) > 0 && s.Id == ids[len(ids)-1] {
+			continue
+		}
+		ids = append(ids, s.Id)
+		values = append(values, s.Value)
+	}
+
+	h := &Header{Type: HeaderType, Version: HeaderVersion, SectionIds: ids}
+	if g.Header != nil {
+		g.Header.SectionIds = h.SectionIds
+	}
+
+	return strings.Join(append([]string{h.Encode()}, values...), "~")
+}
+
+// Returns structure as a base64 raw url encoded string
+// Section ids are sorted, and ids below 1 and duplicates are left out
+func (h *Header) Encode() string {
+	ids := append([]int(nil), h.SectionIds...)
+	sort.Ints(ids)
+	ranges := fibonacciRanges(ids)
+
+	var bitSize int
+	bitSize += bitsType
+	bitSize += bitsVersion
+	bitSize += fibonacciRangeBitSize(ranges)
+
+	e := iabtcfv2.NewTCEncoderFromSize(bitSize)
+	e.WriteInt(h.Type, bitsType)
+	e.WriteInt(h.Version, bitsVersion)
+	writeFibonacciRange(e, ranges)
+
+	return encodeBase64(e.Bytes)
+}
@@ -0,0 +1,172 @@
+package gpp
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/SirDataFR/iabtcfv2"
+)
+
+const tcString = "CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA"
+
+func TestDecode(t *testing.T) {
+	str := "DBACNY~" + tcString + "~1YNN"
+
+	g, err := Decode(str)
+	if err != nil {
+		t.Errorf("GPP String should be decoded without error: %s", err)
+		return
+	}
+
+	if g.Header.Type != HeaderType || g.Header.Version != HeaderVersion || !reflect.DeepEqual(g.SectionIds(), []int{2, 6}) {
+		t.Errorf("Unexpected header: %+v", g.Header)
+	}
+
+	if s := g.Section(SectionIdUSPv1); s == nil || s.Value != "1YNN" {
+		t.Errorf("Section uspv1 should be kept as a raw string")
+	}
+
+	tcData, err := g.TCFEUv2()
+	if err != nil {
+		t.Errorf("Section tcfeuv2 should be decoded without error: %s", err)
+		return
+	}
+	if tcData.CoreString.CmpId != 31 || tcData.ToTCString() != tcString {
+		t.Errorf("Section tcfeuv2 should be decoded as a TC String")
+	}
+
+	if g.Encode() != "DBACNYA~"+tcString+"~1YNN" {
+		t.Errorf("GPP String should be encoded: %s", g.Encode())
+	}
+}
+
+func TestDecodeHeader(t *testing.T) {
+	for _, str := range []string{"DBABM", "DBABMA"} {
+		h, err := DecodeHeader(str)
+		if err != nil {
+			t.Errorf("Header should be decoded without error: %s", err)
+			continue
+		}
+		if !reflect.DeepEqual(h.SectionIds, []int{2}) {
+			t.Errorf("Section ids should be [2]: %v", h.SectionIds)
+		}
+	}
+}
+
+func TestEncodeHeader(t *testing.T) {
+	for _, ids := range [][]int{{}, {2}, {2, 6}, {7, 8, 9, 10, 11, 12}, {2, 3, 6, 8, 9, 12, 100}} {
+		h := &Header{Type: HeaderType, Version: HeaderVersion, SectionIds: ids}
+		decoded, err := DecodeHeader(h.Encode())
+		if err != nil {
+			t.Errorf("Header should be decoded without error: %s", err)
+			continue
+		}
+		if len(decoded.SectionIds) != len(ids) || (len(ids) > 0 && !reflect.DeepEqual(decoded.SectionIds, ids)) {
+			t.Errorf("Section ids should be %v: %v", ids, decoded.SectionIds)
+		}
+	}
+
+	h := &Header{Type: HeaderType, Version: HeaderVersion, SectionIds: []int{6, 0, 2, 2, -1, 7, 6}}
+	decoded, err := DecodeHeader(h.Encode())
+	if err != nil || !reflect.DeepEqual(decoded.SectionIds, []int{2, 6, 7}) {
+		t.Errorf("Section ids below 1 and duplicates should be left out: %v %v", err, decoded)
+	}
+
+	g := &GPPData{Sections: []*Section{{Id: 6, Value: "1YNN"}, {Id: 0, Value: "x"}, {Id: 6, Value: "1YYN"}}}
+	decodedData, err := Decode(g.Encode())
+	if err != nil || !reflect.DeepEqual(decodedData.SectionIds(), []int{6}) || decodedData.Section(6).Value != "1YNN" {
+		t.Errorf("Sections with an id below 1 or a duplicate id should be left out: %v %s", err, g.Encode())
+	}
+}
+
+func TestSetSection(t *testing.T) {
+	g := &GPPData{}
+	g.SetSection(SectionIdUSPv1, "1YNN")
+
+	tcData, err := iabtcfv2.Decode(tcString)
+	if err != nil {
+		t.Errorf("TC String should be decoded without error: %s", err)
+		return
+	}
+	g.SetTCFEUv2(tcData)
+
+	if g.Encode() != "DBACNYA~"+tcString+"~1YNN" {
+		t.Errorf("GPP String should be encoded with sorted sections: %s", g.Encode())
+	}
+
+	g.RemoveSection(SectionIdTCFEUv2)
+	if _, err := g.TCFEUv2(); !errors.Is(err, ErrMissingSection) {
+		t.Errorf("Removed section should be missing: %v", err)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	var decodeErr *DecodeError
+
+	_, err := Decode("DBACNY~" + tcString)
+	if !errors.Is(err, ErrSectionCount) {
+		t.Errorf("Missing section should return ErrSectionCount: %v", err)
+	}
+
+	_, err = Decode("DBAB")
+	if !errors.As(err, &decodeErr) || !errors.Is(err, iabtcfv2.ErrTruncated) || decodeErr.Field != "SectionIds" {
+		t.Errorf("Truncated header should return ErrTruncated: %v", err)
+	}
+
+	// One range of 50,000,000 section ids starting at id 1
+	h := &Header{Type: HeaderType, Version: HeaderVersion}
+	e := iabtcfv2.NewTCEncoderFromSize(bitsType + bitsVersion + bitsNumEntries + bitsIsRange + 64)
+	e.WriteInt(h.Type, bitsType)
+	e.WriteInt(h.Version, bitsVersion)
+	e.WriteInt(1, bitsNumEntries)
+	e.WriteBool(true)
+	e.WriteFibonacciInt(1)
+	e.WriteFibonacciInt(50000000)
+	_, err = DecodeHeader(encodeBase64(e.Bytes))
+	if !errors.As(err, &decodeErr) || !errors.Is(err, iabtcfv2.ErrInvalidValue) || decodeErr.Field != "SectionIds" {
+		t.Errorf("Section id above the max should return ErrInvalidValue: %v", err)
+	}
+
+	// A section id coded with more bits than an int can hold
+	e = iabtcfv2.NewTCEncoderFromSize(bitsType + bitsVersion + bitsNumEntries + bitsIsRange + 100)
+	e.WriteInt(h.Type, bitsType)
+	e.WriteInt(h.Version, bitsVersion)
+	e.WriteInt(1, bitsNumEntries)
+	e.WriteBool(false)
+	e.Position += 97
+	e.WriteBool(true)
+	e.WriteBool(true)
+	_, err = DecodeHeader(encodeBase64(e.Bytes))
+	if !errors.As(err, &decodeErr) || !errors.Is(err, iabtcfv2.ErrInvalidValue) || decodeErr.Field != "SectionIds" {
+		t.Errorf("Fibonacci integer too long should return ErrInvalidValue: %v", err)
+	}
+
+	_, err = Decode("DB*BM~" + tcString)
+	if !errors.Is(err, iabtcfv2.ErrBadBase64) {
+		t.Errorf("Invalid character should return ErrBadBase64: %v", err)
+	}
+
+	_, err = Decode("BBABM~" + tcString)
+	if !errors.Is(err, ErrWrongHeaderType) {
+		t.Errorf("Wrong header type should return ErrWrongHeaderType: %v", err)
+	}
+
+	_, err = Decode("DCABM~" + tcString)
+	if !errors.Is(err, iabtcfv2.ErrUnsupportedVersion) {
+		t.Errorf("Wrong header version should return ErrUnsupportedVersion: %v", err)
+	}
+}
+
+func TestSectionIds(t *testing.T) {
+	// Section ids assigned by the GPP section information registry
+	for id, name := range map[int]string{2: "tcfeuv2", 5: "tcfcav1", 6: "uspv1", 7: "usnat", 8: "usca", 9: "usva", 10: "usco", 11: "usut", 12: "usct"} {
+		if SectionName(id) != name {
+			t.Errorf("Section %d should be %s: %s", id, name, SectionName(id))
+		}
+	}
+
+	if SectionIdTCFCAv1 != 5 {
+		t.Errorf("tcfcav1 section id should be 5: %d", SectionIdTCFCAv1)
+	}
+}
@@ -0,0 +1,449 @@
+package gpp
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SirDataFR/iabtcfv2"
+)
+
+const (
+	CanadaSegmentTypeDisclosedVendors  = 1
+	CanadaSegmentTypePublisherPurposes = 3
+
+	canadaSubsectionSplitter = "."
+)
+
+const (
+	bitsCmpId                       = 12
+	bitsCmpVersion                  = 12
+	bitsConsentScreen               = 6
+	bitsConsentLanguage             = bitsChar * 2
+	bitsVendorListVersion           = 12
+	bitsTcfPolicyVersion            = 6
+	bitsUseNonStandardStacks        = bitsBool
+	bitsSpecialFeatureConsent       = 12
+	bitsPurposesConsent             = 24
+	bitsPubPurposesConsent          = 24
+	bitsNumCustomPurposes           = 6
+	bitsIsRangeEncoding             = bitsBool
+	bitsRangeEntriesVendorIdsRange  = bitsIsRange + bitsVendorId*2
+	bitsRangeEntriesVendorIdsSingle = bitsIsRange + bitsVendorId
+)
+
+// TCFCAv1 is the tcfcav1 section of a GPP String, the IAB Canada TCF
+// DisclosedVendors and PublisherPurposes are nil if the section doesn't contain them
+type TCFCAv1 struct {
+	CoreString        *CanadaCoreString
+	DisclosedVendors  *CanadaDisclosedVendors
+	PublisherPurposes *CanadaPublisherPurposes
+}
+
+// CanadaCoreString is the core segment of the tcfcav1 section
+// It is structurally close to the TCF v2 Core String, with express and implied consents instead of consents and legitimate interests
+type CanadaCoreString struct {
+	Version                      int
+	Created                      time.Time
+	LastUpdated                  time.Time
+	CmpId                        int
+	CmpVersion                   int
+	ConsentScreen                int
+	ConsentLanguage              string
+	VendorListVersion            int
+	TcfPolicyVersion             int
+	UseNonStandardStacks         bool
+	SpecialFeatureExpressConsent map[int]bool
+	PurposesExpressConsent       map[int]bool
+	PurposesImpliedConsent       map[int]bool
+	MaxVendorId                  int
+	IsRangeEncoding              bool
+	VendorExpressConsent         map[int]bool
+	NumEntries                   int
+	RangeEntries                 []*iabtcfv2.RangeEntry
+	MaxVendorIdImplied           int
+	IsRangeEncodingImplied       bool
+	VendorImpliedConsent         map[int]bool
+	NumEntriesImplied            int
+	RangeEntriesImplied          []*iabtcfv2.RangeEntry
+	NumPubRestrictions           int
+	PubRestrictions              []*iabtcfv2.PubRestriction
+}
+
+type CanadaDisclosedVendors struct {
+	SegmentType      int
+	MaxVendorId      int
+	IsRangeEncoding  bool
+	DisclosedVendors map[int]bool
+	NumEntries       int
+	RangeEntries     []*iabtcfv2.RangeEntry
+}
+
+type CanadaPublisherPurposes struct {
+	SegmentType                  int
+	PubPurposesExpressConsent    map[int]bool
+	PubPurposesImpliedConsent    map[int]bool
+	NumCustomPurposes            int
+	CustomPurposesExpressConsent map[int]bool
+	CustomPurposesImpliedConsent map[int]bool
+}
+
+// Decodes the value of a tcfcav1 section and returns it as a TCFCAv1 structure
+// The core segment must come first, and can be followed by the disclosed vendors and publisher purposes segments
+func DecodeTCFCAv1(value string) (t *TCFCAv1, err error) {
+	segments := strings.Split(value, canadaSubsectionSplitter)
+
+	t = &TCFCAv1{}
+	if t.CoreString, err = DecodeCanadaCoreString(segments[0]); err != nil {
+		return nil, err
+	}
+
+	for _, segment := range segments[1:] {
+		d, err := newDecoder(SectionName(SectionIdTCFCAv1), segment)
+		if err != nil {
+			return nil, err
+		}
+
+		segmentType := d.readInt("SegmentType", bitsSegmentType)
+		if d.err != nil {
+			return nil, d.err
+		}
+
+		switch segmentType {
+		case CanadaSegmentTypeDisclosedVendors:
+			if t.DisclosedVendors != nil {
+				return nil, &DecodeError{Section: d.section, Field: "SegmentType", Err: iabtcfv2.ErrDuplicateSegment}
+			}
+			if t.DisclosedVendors, err = DecodeCanadaDisclosedVendors(segment); err != nil {
+				return nil, err
+			}
+		case CanadaSegmentTypePublisherPurposes:
+			if t.PublisherPurposes != nil {
+				return nil, &DecodeError{Section: d.section, Field: "SegmentType", Err: iabtcfv2.ErrDuplicateSegment}
+			}
+			if t.PublisherPurposes, err = DecodeCanadaPublisherPurposes(segment); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, &DecodeError{Section: d.section, Field: "SegmentType", Err: fmt.Errorf("%w: %d", ErrUnknownSection, segmentType)}
+		}
+	}
+
+	return t, nil
+}
+
+// Decodes a tcfcav1 core segment and returns it as a CanadaCoreString structure
+func DecodeCanadaCoreString(segment string) (c *CanadaCoreString, err error) {
+	d, err := newDecoder(SectionName(SectionIdTCFCAv1), segment)
+	if err != nil {
+		return nil, err
+	}
+
+	c = &CanadaCoreString{}
+	c.Version = d.readInt("Version", bitsVersion)
+	c.Created = d.readTime("Created")
+	c.LastUpdated = d.readTime("LastUpdated")
+	c.CmpId = d.readInt("CmpId", bitsCmpId)
+	c.CmpVersion = d.readInt("CmpVersion", bitsCmpVersion)
+	c.ConsentScreen = d.readInt("ConsentScreen", bitsConsentScreen)
+	c.ConsentLanguage = d.readChars("ConsentLanguage", bitsConsentLanguage)
+	c.VendorListVersion = d.readInt("VendorListVersion", bitsVendorListVersion)
+	c.TcfPolicyVersion = d.readInt("TcfPolicyVersion", bitsTcfPolicyVersion)
+	c.UseNonStandardStacks = d.readBool("UseNonStandardStacks")
+	c.SpecialFeatureExpressConsent = d.readBitField("SpecialFeatureExpressConsent", bitsSpecialFeatureConsent)
+	c.PurposesExpressConsent = d.readBitField("PurposesExpressConsent", bitsPurposesConsent)
+	c.PurposesImpliedConsent = d.readBitField("PurposesImpliedConsent", bitsPurposesConsent)
+
+	c.MaxVendorId = d.readInt("MaxVendorId", bitsMaxVendorId)
+	c.IsRangeEncoding = d.readBool("IsRangeEncoding")
+	if c.IsRangeEncoding {
+		c.NumEntries, c.RangeEntries = d.readRangeEntries("RangeEntries")
+	} else {
+		c.VendorExpressConsent = d.readBitField("VendorExpressConsent", uint(c.MaxVendorId))
+	}
+
+	c.MaxVendorIdImplied = d.readInt("MaxVendorIdImplied", bitsMaxVendorId)
+	c.IsRangeEncodingImplied = d.readBool("IsRangeEncodingImplied")
+	if c.IsRangeEncodingImplied {
+		c.NumEntriesImplied, c.RangeEntriesImplied = d.readRangeEntries("RangeEntriesImplied")
+	} else {
+		c.VendorImpliedConsent = d.readBitField("VendorImpliedConsent", uint(c.MaxVendorIdImplied))
+	}
+
+	// Publisher restrictions were added to the core segment after its first release
+	if d.err == nil && d.available() >= bitsNumPubRestrictions {
+		c.NumPubRestrictions, c.PubRestrictions = d.readPubRestrictions("PubRestrictions")
+	}
+
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return c, nil
+}
+
+// Decodes a tcfcav1 disclosed vendors segment and returns it as a CanadaDisclosedVendors structure
+func DecodeCanadaDisclosedVendors(segment string) (dv *CanadaDisclosedVendors, err error) {
+	d, err := newDecoder(SectionName(SectionIdTCFCAv1), segment)
+	if err != nil {
+		return nil, err
+	}
+
+	dv = &CanadaDisclosedVendors{}
+	dv.SegmentType = d.readSegmentType(CanadaSegmentTypeDisclosedVendors)
+	dv.MaxVendorId = d.readInt("MaxVendorId", bitsMaxVendorId)
+	dv.IsRangeEncoding = d.readBool("IsRangeEncoding")
+	if dv.IsRangeEncoding {
+		dv.NumEntries, dv.RangeEntries = d.readRangeEntries("RangeEntries")
+	} else {
+		dv.DisclosedVendors = d.readBitField("DisclosedVendors", uint(dv.MaxVendorId))
+	}
+
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return dv, nil
+}
+
+// Decodes a tcfcav1 publisher purposes segment and returns it as a CanadaPublisherPurposes structure
+func DecodeCanadaPublisherPurposes(segment string) (p *CanadaPublisherPurposes, err error) {
+	d, err := newDecoder(SectionName(SectionIdTCFCAv1), segment)
+	if err != nil {
+		return nil, err
+	}
+
+	p = &CanadaPublisherPurposes{}
+	p.SegmentType = d.readSegmentType(CanadaSegmentTypePublisherPurposes)
+	p.PubPurposesExpressConsent = d.readBitField("PubPurposesExpressConsent", bitsPubPurposesConsent)
+	p.PubPurposesImpliedConsent = d.readBitField("PubPurposesImpliedConsent", bitsPubPurposesConsent)
+	p.NumCustomPurposes = d.readInt("NumCustomPurposes", bitsNumCustomPurposes)
+	p.CustomPurposesExpressConsent = d.readBitField("CustomPurposesExpressConsent", uint(p.NumCustomPurposes))
+	p.CustomPurposesImpliedConsent = d.readBitField("CustomPurposesImpliedConsent", uint(p.NumCustomPurposes))
+
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return p, nil
+}
+
+// Returns true if user has given express consent to special feature id
+func (c *CanadaCoreString) IsSpecialFeatureAllowed(id int) bool {
+	return c.SpecialFeatureExpressConsent[id]
+}
+
+// Returns true if user has given express consent to purpose id
+func (c *CanadaCoreString) IsPurposeExpressConsentAllowed(id int) bool {
+	return c.PurposesExpressConsent[id]
+}
+
+// Returns true if implied consent is established for purpose id
+func (c *CanadaCoreString) IsPurposeImpliedConsentAllowed(id int) bool {
+	return c.PurposesImpliedConsent[id]
+}
+
+// Returns true if user has given express consent to vendor id
+func (c *CanadaCoreString) IsVendorExpressConsentAllowed(id int) bool {
+	if c.IsRangeEncoding {
+		return isInRangeEntries(c.RangeEntries, id)
+	}
+	return c.VendorExpressConsent[id]
+}
+
+// Returns true if implied consent is established for vendor id
+func (c *CanadaCoreString) IsVendorImpliedConsentAllowed(id int) bool {
+	if c.IsRangeEncodingImplied {
+		return isInRangeEntries(c.RangeEntriesImplied, id)
+	}
+	return c.VendorImpliedConsent[id]
+}
+
+// Returns true if vendor id is disclosed
+func (dv *CanadaDisclosedVendors) IsVendorDisclosed(id int) bool {
+	if dv.IsRangeEncoding {
+		return isInRangeEntries(dv.RangeEntries, id)
+	}
+	return dv.DisclosedVendors[id]
+}
+
+// Returns true if user has given express consent to standard purpose id
+func (p *CanadaPublisherPurposes) IsPurposeExpressConsentAllowed(id int) bool {
+	return p.PubPurposesExpressConsent[id]
+}
+
+// Returns true if implied consent is established for standard purpose id
+func (p *CanadaPublisherPurposes) IsPurposeImpliedConsentAllowed(id int) bool {
+	return p.PubPurposesImpliedConsent[id]
+}
+
+// Returns true if user has given express consent to custom purpose id
+func (p *CanadaPublisherPurposes) IsCustomPurposeExpressConsentAllowed(id int) bool {
+	return p.CustomPurposesExpressConsent[id]
+}
+
+// Returns true if implied consent is established for custom purpose id
+func (p *CanadaPublisherPurposes) IsCustomPurposeImpliedConsentAllowed(id int) bool {
+	return p.CustomPurposesImpliedConsent[id]
+}
+
+func isInRangeEntries(entries []*iabtcfv2.RangeEntry, id int) bool {
+	for _, entry := range entries {
+		if entry.StartVendorID <= id && id <= entry.EndVendorID {
+			return true
+		}
+	}
+	return false
+}
+
+func rangeEntriesBitSize(entries []*iabtcfv2.RangeEntry) int {
+	bitSize := bitsNumEntries
+	for _, entry := range entries {
+		if entry.EndVendorID > entry.StartVendorID {
+			bitSize += bitsRangeEntriesVendorIdsRange
+		} else {
+			bitSize += bitsRangeEntriesVendorIdsSingle
+		}
+	}
+	return bitSize
+}
+
+// Returns the number of bits of a vendor section, and sets maxVendorId from the bit field if it is 0
+func vendorsBitSize(maxVendorId *int, isRangeEncoding bool, vendors map[int]bool, entries []*iabtcfv2.RangeEntry) int {
+	bitSize := bitsMaxVendorId + bitsIsRangeEncoding
+	if isRangeEncoding {
+		return bitSize + rangeEntriesBitSize(entries)
+	}
+
+	if *maxVendorId == 0 {
+		for id := range vendors {
+			if id > *maxVendorId {
+				*maxVendorId = id
+			}
+		}
+	}
+	return bitSize + *maxVendorId
+}
+
+// Returns structure as a tcfcav1 section value, with the segments that are not nil
+func (t *TCFCAv1) Encode() string {
+	segments := []string{t.CoreString.Encode()}
+	if t.DisclosedVendors != nil {
+		segments = append(segments, t.DisclosedVendors.Encode())
+	}
+	if t.PublisherPurposes != nil {
+		segments = append(segments, t.PublisherPurposes.Encode())
+	}
+	return strings.Join(segments, canadaSubsectionSplitter)
+}
+
+// Returns structure as a base64 raw url encoded string
+func (c *CanadaCoreString) Encode() string {
+	var bitSize int
+	bitSize += bitsVersion
+	bitSize += bitsTime
+	bitSize += bitsTime
+	bitSize += bitsCmpId
+	bitSize += bitsCmpVersion
+	bitSize += bitsConsentScreen
+	bitSize += bitsConsentLanguage
+	bitSize += bitsVendorListVersion
+	bitSize += bitsTcfPolicyVersion
+	bitSize += bitsUseNonStandardStacks
+	bitSize += bitsSpecialFeatureConsent
+	bitSize += bitsPurposesConsent
+	bitSize += bitsPurposesConsent
+	bitSize += vendorsBitSize(&c.MaxVendorId, c.IsRangeEncoding, c.VendorExpressConsent, c.RangeEntries)
+	bitSize += vendorsBitSize(&c.MaxVendorIdImplied, c.IsRangeEncodingImplied, c.VendorImpliedConsent, c.RangeEntriesImplied)
+	bitSize += bitsNumPubRestrictions
+	for _, r := range c.PubRestrictions {
+		bitSize += bitsPubRestrictionsEntryPurposeId + bitsPubRestrictionsEntryRestrictionType + rangeEntriesBitSize(r.RangeEntries)
+	}
+
+	e := iabtcfv2.NewTCEncoderFromSize(bitSize)
+	e.WriteInt(c.Version, bitsVersion)
+	e.WriteTime(c.Created)
+	e.WriteTime(c.LastUpdated)
+	e.WriteInt(c.CmpId, bitsCmpId)
+	e.WriteInt(c.CmpVersion, bitsCmpVersion)
+	e.WriteInt(c.ConsentScreen, bitsConsentScreen)
+	e.WriteChars(c.ConsentLanguage, bitsConsentLanguage)
+	e.WriteInt(c.VendorListVersion, bitsVendorListVersion)
+	e.WriteInt(c.TcfPolicyVersion, bitsTcfPolicyVersion)
+	e.WriteBool(c.UseNonStandardStacks)
+	e.WriteBools(c.IsSpecialFeatureAllowed, bitsSpecialFeatureConsent)
+	e.WriteBools(c.IsPurposeExpressConsentAllowed, bitsPurposesConsent)
+	e.WriteBools(c.IsPurposeImpliedConsentAllowed, bitsPurposesConsent)
+
+	e.WriteInt(c.MaxVendorId, bitsMaxVendorId)
+	e.WriteBool(c.IsRangeEncoding)
+	if c.IsRangeEncoding {
+		e.WriteRangeEntries(c.RangeEntries)
+	} else {
+		e.WriteBools(c.IsVendorExpressConsentAllowed, c.MaxVendorId)
+	}
+
+	e.WriteInt(c.MaxVendorIdImplied, bitsMaxVendorId)
+	e.WriteBool(c.IsRangeEncodingImplied)
+	if c.IsRangeEncodingImplied {
+		e.WriteRangeEntries(c.RangeEntriesImplied)
+	} else {
+		e.WriteBools(c.IsVendorImpliedConsentAllowed, c.MaxVendorIdImplied)
+	}
+
+	e.WritePubRestrictions(c.PubRestrictions)
+
+	return encodeBase64(e.Bytes)
+}
+
+// Returns structure as a base64 raw url encoded string
+func (dv *CanadaDisclosedVendors) Encode() string {
+	var bitSize int
+	bitSize += bitsSegmentType
+	bitSize += vendorsBitSize(&dv.MaxVendorId, dv.IsRangeEncoding, dv.DisclosedVendors, dv.RangeEntries)
+
+	e := iabtcfv2.NewTCEncoderFromSize(bitSize)
+	e.WriteInt(CanadaSegmentTypeDisclosedVendors, bitsSegmentType)
+	e.WriteInt(dv.MaxVendorId, bitsMaxVendorId)
+	e.WriteBool(dv.IsRangeEncoding)
+	if dv.IsRangeEncoding {
+		e.WriteRangeEntries(dv.RangeEntries)
+	} else {
+		e.WriteBools(dv.IsVendorDisclosed, dv.MaxVendorId)
+	}
+
+	return encodeBase64(e.Bytes)
+}
+
+// Returns structure as a base64 raw url encoded string
+func (p *CanadaPublisherPurposes) Encode() string {
+	var bitSize int
+	bitSize += bitsSegmentType
+	bitSize += bitsPubPurposesConsent * 2
+	bitSize += bitsNumCustomPurposes
+	bitSize += p.NumCustomPurposes * 2
+
+	e := iabtcfv2.NewTCEncoderFromSize(bitSize)
+	e.WriteInt(CanadaSegmentTypePublisherPurposes, bitsSegmentType)
+	e.WriteBools(p.IsPurposeExpressConsentAllowed, bitsPubPurposesConsent)
+	e.WriteBools(p.IsPurposeImpliedConsentAllowed, bitsPubPurposesConsent)
+	e.WriteInt(p.NumCustomPurposes, bitsNumCustomPurposes)
+	e.WriteBools(p.IsCustomPurposeExpressConsentAllowed, p.NumCustomPurposes)
+	e.WriteBools(p.IsCustomPurposeImpliedConsentAllowed, p.NumCustomPurposes)
+
+	return encodeBase64(e.Bytes)
+}
+
+// Decodes the tcfcav1 section
+func (g *GPPData) TCFCAv1() (*TCFCAv1, error) {
+	s := g.Section(SectionIdTCFCAv1)
+	if s == nil {
+		return nil, &DecodeError{Section: SectionName(SectionIdTCFCAv1), Err: ErrMissingSection}
+	}
+	return DecodeTCFCAv1(s.Value)
+}
+
+// Sets the tcfcav1 section
+func (g *GPPData) SetTCFCAv1(t *TCFCAv1) {
+	g.SetSection(SectionIdTCFCAv1, t.Encode())
+}
@@ -0,0 +1,211 @@
+package gpp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SirDataFR/iabtcfv2"
+)
+
+func newTestTCFCAv1() *TCFCAv1 {
+	created := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	return &TCFCAv1{
+		CoreString: &CanadaCoreString{
+			Version:                      1,
+			Created:                      created,
+			LastUpdated:                  created,
+			CmpId:                        92,
+			CmpVersion:                   3,
+			ConsentScreen:                1,
+			ConsentLanguage:              "FR",
+			VendorListVersion:            50,
+			TcfPolicyVersion:             2,
+			SpecialFeatureExpressConsent: map[int]bool{1: true},
+			PurposesExpressConsent:       map[int]bool{1: true, 2: true, 3: true},
+			PurposesImpliedConsent:       map[int]bool{7: true, 9: true},
+			MaxVendorId:                  8,
+			VendorExpressConsent:         map[int]bool{2: true, 8: true},
+			MaxVendorIdImplied:           300,
+			IsRangeEncodingImplied:       true,
+			NumEntriesImplied:            2,
+			RangeEntriesImplied:          []*iabtcfv2.RangeEntry{{StartVendorID: 10, EndVendorID: 20}, {StartVendorID: 300, EndVendorID: 300}},
+			NumPubRestrictions:           1,
+			PubRestrictions: []*iabtcfv2.PubRestriction{
+				{PurposeId: 2, RestrictionType: iabtcfv2.RestrictionTypeRequireConsent, NumEntries: 1, RangeEntries: []*iabtcfv2.RangeEntry{{StartVendorID: 8, EndVendorID: 8}}},
+			},
+		},
+		DisclosedVendors: &CanadaDisclosedVendors{
+			SegmentType:     CanadaSegmentTypeDisclosedVendors,
+			MaxVendorId:     300,
+			IsRangeEncoding: true,
+			NumEntries:      1,
+			RangeEntries:    []*iabtcfv2.RangeEntry{{StartVendorID: 1, EndVendorID: 300}},
+		},
+		PublisherPurposes: &CanadaPublisherPurposes{
+			SegmentType:                  CanadaSegmentTypePublisherPurposes,
+			PubPurposesExpressConsent:    map[int]bool{1: true},
+			PubPurposesImpliedConsent:    map[int]bool{10: true},
+			NumCustomPurposes:            2,
+			CustomPurposesExpressConsent: map[int]bool{2: true},
+			CustomPurposesImpliedConsent: map[int]bool{1: true},
+		},
+	}
+}
+
+func TestTCFCAv1(t *testing.T) {
+	tcfca := newTestTCFCAv1()
+
+	g := &GPPData{}
+	g.SetTCFCAv1(tcfca)
+
+	decoded, err := Decode(g.Encode())
+	if err != nil {
+		t.Errorf("GPP String should be decoded without error: %s", err)
+		return
+	}
+	if decoded.SectionIds()[0] != SectionIdTCFCAv1 {
+		t.Errorf("Section tcfcav1 should have id 5: %v", decoded.SectionIds())
+	}
+
+	d, err := decoded.TCFCAv1()
+	if err != nil {
+		t.Errorf("Section tcfcav1 should be decoded without error: %s", err)
+		return
+	}
+	if d.Encode() != tcfca.Encode() {
+		t.Errorf("Section tcfcav1 should be decoded as encoded")
+	}
+
+	c := d.CoreString
+	if c.CmpId != 92 || c.ConsentLanguage != "FR" || !c.Created.Equal(tcfca.CoreString.Created) || c.VendorListVersion != 50 {
+		t.Errorf("Unexpected core segment: %+v", c)
+	}
+	if !c.IsSpecialFeatureAllowed(1) || !c.IsPurposeExpressConsentAllowed(3) || c.IsPurposeExpressConsentAllowed(7) ||
+		!c.IsPurposeImpliedConsentAllowed(7) || c.IsPurposeImpliedConsentAllowed(1) {
+		t.Errorf("Purposes should be decoded")
+	}
+	if !c.IsVendorExpressConsentAllowed(8) || c.IsVendorExpressConsentAllowed(3) ||
+		!c.IsVendorImpliedConsentAllowed(15) || !c.IsVendorImpliedConsentAllowed(300) || c.IsVendorImpliedConsentAllowed(21) {
+		t.Errorf("Vendors should be decoded")
+	}
+	if c.NumPubRestrictions != 1 || c.PubRestrictions[0].PurposeId != 2 || !c.PubRestrictions[0].IsVendorIncluded(8) {
+		t.Errorf("Publisher restrictions should be decoded")
+	}
+
+	if !d.DisclosedVendors.IsVendorDisclosed(150) || d.DisclosedVendors.IsVendorDisclosed(301) {
+		t.Errorf("Disclosed vendors should be decoded")
+	}
+
+	p := d.PublisherPurposes
+	if !p.IsPurposeExpressConsentAllowed(1) || !p.IsPurposeImpliedConsentAllowed(10) ||
+		!p.IsCustomPurposeExpressConsentAllowed(2) || !p.IsCustomPurposeImpliedConsentAllowed(1) || p.IsCustomPurposeImpliedConsentAllowed(2) {
+		t.Errorf("Publisher purposes should be decoded")
+	}
+}
+
+// Returns the base64 of bits given as groups of '0' and '1', padded with zeros to a whole byte
+func bitsToBase64(groups ...string) string {
+	bits := strings.Join(groups, "")
+	b := make([]byte, (len(bits)+7)/8)
+	for i, c := range bits {
+		if c == '1' {
+			b[i/8] |= 1 << uint(7-i%8)
+		}
+	}
+	return encodeBase64(b)
+}
+
+// The reference segments are written bit by bit from the field table of the tcfcav1 specification,
+// so that a wrong field order or width in the encoder and decoder can't go unnoticed
+func TestDecodeTCFCAv1Reference(t *testing.T) {
+	core := bitsToBase64(
+		"000001",                               // Version 1
+		"001111010011011001011001011000000000", // Created 2022-01-26T00:00:00Z in deciseconds
+		"001111010011011001011001011000000000", // LastUpdated
+		"000001011100",                         // CmpId 92
+		"000000000011",                         // CmpVersion 3
+		"000001",                               // ConsentScreen 1
+		"000101010001",                         // ConsentLanguage "FR"
+		"000000110010",                         // VendorListVersion 50
+		"000010",                               // TcfPolicyVersion 2
+		"1",                                    // UseNonStandardStacks
+		"100000000000",                         // SpecialFeatureExpressConsent 1
+		"111000000000000000000000",             // PurposesExpressConsent 1, 2, 3
+		"000000101000000000000000",             // PurposesImpliedConsent 7, 9
+		"0000000000001000", "0", "01000001",    // VendorExpressConsent: MaxVendorId 8, bit field of 2 and 8
+		"0000000100101100", "1", "000000000010", // VendorImpliedConsent: MaxVendorId 300, 2 range entries
+		"1", "0000000000001010", "0000000000010100", // 10-20
+		"0", "0000000100101100", // 300
+		"000000000001",                 // NumPubRestrictions 1
+		"000010", "01", "000000000001", // purpose 2, require consent, 1 entry
+		"0", "0000000000001000", // vendor 8
+	)
+	disclosed := bitsToBase64(
+		"001",                                   // SegmentType 1
+		"0000000100101100", "1", "000000000001", // MaxVendorId 300, 1 range entry
+		"1", "0000000000000001", "0000000100101100", // 1-300
+	)
+
+	d, err := DecodeTCFCAv1(core + "." + disclosed)
+	if err != nil {
+		t.Errorf("Reference section should be decoded without error: %s", err)
+		return
+	}
+
+	c := d.CoreString
+	if c.Version != 1 || !c.Created.Equal(time.Date(2022, 1, 26, 0, 0, 0, 0, time.UTC)) || !c.LastUpdated.Equal(c.Created) ||
+		c.CmpId != 92 || c.CmpVersion != 3 || c.ConsentScreen != 1 || c.ConsentLanguage != "FR" ||
+		c.VendorListVersion != 50 || c.TcfPolicyVersion != 2 || !c.UseNonStandardStacks {
+		t.Errorf("Unexpected core segment fields: %+v", c)
+	}
+	if !c.IsSpecialFeatureAllowed(1) || c.IsSpecialFeatureAllowed(2) ||
+		!c.IsPurposeExpressConsentAllowed(1) || !c.IsPurposeExpressConsentAllowed(3) || c.IsPurposeExpressConsentAllowed(4) ||
+		!c.IsPurposeImpliedConsentAllowed(7) || !c.IsPurposeImpliedConsentAllowed(9) || c.IsPurposeImpliedConsentAllowed(8) {
+		t.Errorf("Reference purposes should be decoded")
+	}
+	if c.MaxVendorId != 8 || c.IsRangeEncoding || !c.IsVendorExpressConsentAllowed(2) || !c.IsVendorExpressConsentAllowed(8) || c.IsVendorExpressConsentAllowed(3) {
+		t.Errorf("Reference express consent vendors should be decoded: %+v", c.VendorExpressConsent)
+	}
+	if c.MaxVendorIdImplied != 300 || !c.IsRangeEncodingImplied || c.NumEntriesImplied != 2 ||
+		!c.IsVendorImpliedConsentAllowed(10) || !c.IsVendorImpliedConsentAllowed(20) || !c.IsVendorImpliedConsentAllowed(300) || c.IsVendorImpliedConsentAllowed(21) {
+		t.Errorf("Reference implied consent vendors should be decoded: %+v", c.RangeEntriesImplied)
+	}
+	if c.NumPubRestrictions != 1 || c.PubRestrictions[0].PurposeId != 2 || c.PubRestrictions[0].RestrictionType != iabtcfv2.RestrictionTypeRequireConsent ||
+		!c.PubRestrictions[0].IsVendorIncluded(8) || c.PubRestrictions[0].IsVendorIncluded(9) {
+		t.Errorf("Reference publisher restrictions should be decoded: %+v", c.PubRestrictions)
+	}
+	if d.DisclosedVendors == nil || !d.DisclosedVendors.IsVendorDisclosed(1) || !d.DisclosedVendors.IsVendorDisclosed(300) || d.DisclosedVendors.IsVendorDisclosed(301) {
+		t.Errorf("Reference disclosed vendors should be decoded")
+	}
+
+	if d.Encode() != core+"."+disclosed {
+		t.Errorf("Reference section should be encoded as decoded: %s", d.Encode())
+	}
+}
+
+func TestDecodeTCFCAv1Errors(t *testing.T) {
+	tcfca := newTestTCFCAv1()
+	core := tcfca.CoreString.Encode()
+
+	_, err := DecodeTCFCAv1(core[:20])
+	if !errors.Is(err, iabtcfv2.ErrTruncated) {
+		t.Errorf("Truncated core segment should return ErrTruncated: %v", err)
+	}
+
+	_, err = DecodeTCFCAv1(core + "." + tcfca.DisclosedVendors.Encode() + "." + tcfca.DisclosedVendors.Encode())
+	if !errors.Is(err, iabtcfv2.ErrDuplicateSegment) {
+		t.Errorf("Duplicate segment should return ErrDuplicateSegment: %v", err)
+	}
+
+	_, err = DecodeTCFCAv1(core + ".QA")
+	if !errors.Is(err, ErrUnknownSection) {
+		t.Errorf("Unknown segment type should return ErrUnknownSection: %v", err)
+	}
+
+	_, err = DecodeCanadaPublisherPurposes(tcfca.DisclosedVendors.Encode())
+	if !errors.Is(err, iabtcfv2.ErrWrongSegmentType) {
+		t.Errorf("Wrong segment type should return ErrWrongSegmentType: %v", err)
+	}
+}
@@ -0,0 +1,395 @@
+package gpp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SirDataFR/iabtcfv2"
+)
+
+// Values of the 2 bits fields of US sections
+// For opt-outs, USValueYes means the user opted out; for notices, that the notice was provided;
+// for consents such as SensitiveDataProcessing in states requiring consent, that the user didn't consent
+const (
+	USValueNotApplicable = 0
+	USValueYes           = 1
+	USValueNo            = 2
+)
+
+const (
+	bitsUSField          = 2
+	bitsSubsectionType   = 2
+	bitsGpc              = bitsBool
+	subsectionTypeGpc    = 1
+	usSubsectionSplitter = "."
+)
+
+// USSection is a US National (usnat) or US state section of a GPP String
+// Fields that don't exist in the section are left to 0
+// SensitiveDataProcessingCategories and KnownChildSensitiveDataConsents hold one value per category, category 1 being at index 0
+type USSection struct {
+	SectionId                           int
+	Version                             int
+	SharingNotice                       int
+	SaleOptOutNotice                    int
+	SharingOptOutNotice                 int
+	TargetedAdvertisingOptOutNotice     int
+	SensitiveDataProcessingOptOutNotice int
+	SensitiveDataLimitUseNotice         int
+	SaleOptOut                          int
+	SharingOptOut                       int
+	TargetedAdvertisingOptOut           int
+	SensitiveDataProcessingCategories   []int
+	KnownChildSensitiveDataConsents     []int
+	PersonalDataConsents                int
+	MspaCoveredTransaction              int
+	MspaOptOutOptionMode                int
+	MspaServiceProviderMode             int
+	GpcSegmentIncluded                  bool
+	Gpc                                 bool
+}
+
+type usField int
+
+const (
+	usFieldSharingNotice usField = iota
+	usFieldSaleOptOutNotice
+	usFieldSharingOptOutNotice
+	usFieldTargetedAdvertisingOptOutNotice
+	usFieldSensitiveDataProcessingOptOutNotice
+	usFieldSensitiveDataLimitUseNotice
+	usFieldSaleOptOut
+	usFieldSharingOptOut
+	usFieldTargetedAdvertisingOptOut
+	usFieldSensitiveDataProcessing
+	usFieldKnownChildSensitiveDataConsents
+	usFieldPersonalDataConsents
+	usFieldMspaCoveredTransaction
+	usFieldMspaOptOutOptionMode
+	usFieldMspaServiceProviderMode
+)
+
+var usFieldNames = map[usField]string{
+	usFieldSharingNotice:                       "SharingNotice",
+	usFieldSaleOptOutNotice:                    "SaleOptOutNotice",
+	usFieldSharingOptOutNotice:                 "SharingOptOutNotice",
+	usFieldTargetedAdvertisingOptOutNotice:     "TargetedAdvertisingOptOutNotice",
+	usFieldSensitiveDataProcessingOptOutNotice: "SensitiveDataProcessingOptOutNotice",
+	usFieldSensitiveDataLimitUseNotice:         "SensitiveDataLimitUseNotice",
+	usFieldSaleOptOut:                          "SaleOptOut",
+	usFieldSharingOptOut:                       "SharingOptOut",
+	usFieldTargetedAdvertisingOptOut:           "TargetedAdvertisingOptOut",
+	usFieldSensitiveDataProcessing:             "SensitiveDataProcessing",
+	usFieldKnownChildSensitiveDataConsents:     "KnownChildSensitiveDataConsents",
+	usFieldPersonalDataConsents:                "PersonalDataConsents",
+	usFieldMspaCoveredTransaction:              "MspaCoveredTransaction",
+	usFieldMspaOptOutOptionMode:                "MspaOptOutOptionMode",
+	usFieldMspaServiceProviderMode:             "MspaServiceProviderMode",
+}
+
+// usLayout is the ordered list of fields of a version of a US section
+// gpc is true if the section can be followed by a GPC subsection
+type usLayout struct {
+	fields                             []usField
+	numSensitiveDataProcessing         int
+	numKnownChildSensitiveDataConsents int
+	gpc                                bool
+}
+
+type usLayoutKey struct {
+	sectionId int
+	version   int
+}
+
+var usLayouts = map[usLayoutKey]*usLayout{
+	{SectionIdUSNat, 1}: {
+		fields: []usField{
+			usFieldSharingNotice, usFieldSaleOptOutNotice, usFieldSharingOptOutNotice, usFieldTargetedAdvertisingOptOutNotice,
+			usFieldSensitiveDataProcessingOptOutNotice, usFieldSensitiveDataLimitUseNotice,
+			usFieldSaleOptOut, usFieldSharingOptOut, usFieldTargetedAdvertisingOptOut,
+			usFieldSensitiveDataProcessing, usFieldKnownChildSensitiveDataConsents, usFieldPersonalDataConsents,
+			usFieldMspaCoveredTransaction, usFieldMspaOptOutOptionMode, usFieldMspaServiceProviderMode,
+		},
+		numSensitiveDataProcessing:         12,
+		numKnownChildSensitiveDataConsents: 2,
+		gpc:                                true,
+	},
+	{SectionIdUSNat, 2}: {
+		fields: []usField{
+			usFieldSharingNotice, usFieldSaleOptOutNotice, usFieldSharingOptOutNotice, usFieldTargetedAdvertisingOptOutNotice,
+			usFieldSensitiveDataProcessingOptOutNotice, usFieldSensitiveDataLimitUseNotice,
+			usFieldSaleOptOut, usFieldSharingOptOut, usFieldTargetedAdvertisingOptOut,
+			usFieldSensitiveDataProcessing, usFieldKnownChildSensitiveDataConsents, usFieldPersonalDataConsents,
+			usFieldMspaCoveredTransaction, usFieldMspaOptOutOptionMode, usFieldMspaServiceProviderMode,
+		},
+		numSensitiveDataProcessing:         16,
+		numKnownChildSensitiveDataConsents: 3,
+		gpc:                                true,
+	},
+	{SectionIdUSCA, 1}: {
+		fields: []usField{
+			usFieldSaleOptOutNotice, usFieldSharingOptOutNotice, usFieldSensitiveDataLimitUseNotice,
+			usFieldSaleOptOut, usFieldSharingOptOut,
+			usFieldSensitiveDataProcessing, usFieldKnownChildSensitiveDataConsents, usFieldPersonalDataConsents,
+			usFieldMspaCoveredTransaction, usFieldMspaOptOutOptionMode, usFieldMspaServiceProviderMode,
+		},
+		numSensitiveDataProcessing:         9,
+		numKnownChildSensitiveDataConsents: 2,
+		gpc:                                true,
+	},
+	{SectionIdUSVA, 1}: {
+		fields: []usField{
+			usFieldSharingNotice, usFieldSaleOptOutNotice, usFieldTargetedAdvertisingOptOutNotice,
+			usFieldSaleOptOut, usFieldTargetedAdvertisingOptOut,
+			usFieldSensitiveDataProcessing, usFieldKnownChildSensitiveDataConsents,
+			usFieldMspaCoveredTransaction, usFieldMspaOptOutOptionMode, usFieldMspaServiceProviderMode,
+		},
+		numSensitiveDataProcessing:         8,
+		numKnownChildSensitiveDataConsents: 1,
+	},
+	{SectionIdUSCO, 1}: {
+		fields: []usField{
+			usFieldSharingNotice, usFieldSaleOptOutNotice, usFieldTargetedAdvertisingOptOutNotice,
+			usFieldSaleOptOut, usFieldTargetedAdvertisingOptOut,
+			usFieldSensitiveDataProcessing, usFieldKnownChildSensitiveDataConsents,
+			usFieldMspaCoveredTransaction, usFieldMspaOptOutOptionMode, usFieldMspaServiceProviderMode,
+		},
+		numSensitiveDataProcessing:         7,
+		numKnownChildSensitiveDataConsents: 1,
+		gpc:                                true,
+	},
+	{SectionIdUSUT, 1}: {
+		fields: []usField{
+			usFieldSharingNotice, usFieldSaleOptOutNotice, usFieldTargetedAdvertisingOptOutNotice, usFieldSensitiveDataProcessingOptOutNotice,
+			usFieldSaleOptOut, usFieldTargetedAdvertisingOptOut,
+			usFieldSensitiveDataProcessing, usFieldKnownChildSensitiveDataConsents,
+			usFieldMspaCoveredTransaction, usFieldMspaOptOutOptionMode, usFieldMspaServiceProviderMode,
+		},
+		numSensitiveDataProcessing:         8,
+		numKnownChildSensitiveDataConsents: 1,
+	},
+	{SectionIdUSCT, 1}: {
+		fields: []usField{
+			usFieldSharingNotice, usFieldSaleOptOutNotice, usFieldTargetedAdvertisingOptOutNotice,
+			usFieldSaleOptOut, usFieldTargetedAdvertisingOptOut,
+			usFieldSensitiveDataProcessing, usFieldKnownChildSensitiveDataConsents,
+			usFieldMspaCoveredTransaction, usFieldMspaOptOutOptionMode, usFieldMspaServiceProviderMode,
+		},
+		numSensitiveDataProcessing:         8,
+		numKnownChildSensitiveDataConsents: 3,
+		gpc:                                true,
+	},
+}
+
+// Returns true if section id is usnat or a US state section
+func IsUSSection(id int) bool {
+	return id >= SectionIdUSNat && id <= SectionIdUSCT
+}
+
+// Returns a pointer to the 2 bits field f
+func (s *USSection) field(f usField) *int {
+	switch f {
+	case usFieldSharingNotice:
+		return &s.SharingNotice
+	case usFieldSaleOptOutNotice:
+		return &s.SaleOptOutNotice
+	case usFieldSharingOptOutNotice:
+		return &s.SharingOptOutNotice
+	case usFieldTargetedAdvertisingOptOutNotice:
+		return &s.TargetedAdvertisingOptOutNotice
+	case usFieldSensitiveDataProcessingOptOutNotice:
+		return &s.SensitiveDataProcessingOptOutNotice
+	case usFieldSensitiveDataLimitUseNotice:
+		return &s.SensitiveDataLimitUseNotice
+	case usFieldSaleOptOut:
+		return &s.SaleOptOut
+	case usFieldSharingOptOut:
+		return &s.SharingOptOut
+	case usFieldTargetedAdvertisingOptOut:
+		return &s.TargetedAdvertisingOptOut
+	case usFieldPersonalDataConsents:
+		return &s.PersonalDataConsents
+	case usFieldMspaCoveredTransaction:
+		return &s.MspaCoveredTransaction
+	case usFieldMspaOptOutOptionMode:
+		return &s.MspaOptOutOptionMode
+	case usFieldMspaServiceProviderMode:
+		return &s.MspaServiceProviderMode
+	}
+	return nil
+}
+
+// Decodes the value of US section id and returns it as a USSection structure
+func DecodeUSSection(sectionId int, value string) (s *USSection, err error) {
+	if !IsUSSection(sectionId) {
+		return nil, &DecodeError{Section: SectionName(sectionId), Err: fmt.Errorf("%w: not a US section", ErrUnknownSection)}
+	}
+
+	subsections := strings.Split(value, usSubsectionSplitter)
+	d, err := newDecoder(SectionName(sectionId), subsections[0])
+	if err != nil {
+		return nil, err
+	}
+
+	s = &USSection{SectionId: sectionId}
+	s.Version = d.readInt("Version", bitsVersion)
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	layout, ok := usLayouts[usLayoutKey{sectionId, s.Version}]
+	if !ok {
+		return nil, &DecodeError{Section: d.section, Field: "Version", Err: fmt.Errorf("%w: %d", iabtcfv2.ErrUnsupportedVersion, s.Version)}
+	}
+
+	for _, f := range layout.fields {
+		switch f {
+		case usFieldSensitiveDataProcessing:
+			s.SensitiveDataProcessingCategories = d.readNBitField(usFieldNames[f], layout.numSensitiveDataProcessing, bitsUSField)
+		case usFieldKnownChildSensitiveDataConsents:
+			s.KnownChildSensitiveDataConsents = d.readNBitField(usFieldNames[f], layout.numKnownChildSensitiveDataConsents, bitsUSField)
+		default:
+			*s.field(f) = d.readInt(usFieldNames[f], bitsUSField)
+		}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	for _, subsection := range subsections[1:] {
+		d, err := newDecoder(d.section, subsection)
+		if err != nil {
+			return nil, err
+		}
+
+		subsectionType := d.readInt("SubsectionType", bitsSubsectionType)
+		gpc := d.readBool("Gpc")
+		if d.err != nil {
+			return nil, d.err
+		}
+		if subsectionType != subsectionTypeGpc {
+			return nil, &DecodeError{Section: d.section, Field: "SubsectionType", Err: fmt.Errorf("%w: subsection type %d", ErrUnknownSection, subsectionType)}
+		}
+		if !layout.gpc {
+			return nil, &DecodeError{Section: d.section, Field: "SubsectionType", Err: fmt.Errorf("%w: no GPC subsection in %s", ErrUnknownSection, d.section)}
+		}
+		s.GpcSegmentIncluded = true
+		s.Gpc = gpc
+	}
+
+	return s, nil
+}
+
+// Returns true if user opted out of the sale of their personal data
+func (s *USSection) IsSaleOptedOut() bool {
+	return s.SaleOptOut == USValueYes
+}
+
+// Returns true if user opted out of the sharing of their personal data
+// In usca, sharing is the sharing for cross-context behavioral advertising
+func (s *USSection) IsSharingOptedOut() bool {
+	return s.SharingOptOut == USValueYes
+}
+
+// Returns true if user opted out of the processing of their personal data for targeted advertising
+func (s *USSection) IsTargetedAdvertisingOptedOut() bool {
+	return s.TargetedAdvertisingOptOut == USValueYes
+}
+
+// Returns the value of sensitive data category i, starting from 1, or USValueNotApplicable if it doesn't exist
+// In usnat and opt-out states, USValueYes means the user opted out;
+// in states requiring consent, such as usva, USValueYes means the user didn't consent
+func (s *USSection) SensitiveDataProcessing(i int) int {
+	if i < 1 || i > len(s.SensitiveDataProcessingCategories) {
+		return USValueNotApplicable
+	}
+	return s.SensitiveDataProcessingCategories[i-1]
+}
+
+// Returns structure as a base64 raw url encoded string, followed by the GPC subsection if included and defined for the section
+// Returns an empty string if the section id and version are not supported
+func (s *USSection) Encode() string {
+	layout, ok := usLayouts[usLayoutKey{s.SectionId, s.Version}]
+	if !ok {
+		return ""
+	}
+
+	var bitSize int
+	bitSize += bitsVersion
+	for _, f := range layout.fields {
+		switch f {
+		case usFieldSensitiveDataProcessing:
+			bitSize += layout.numSensitiveDataProcessing * bitsUSField
+		case usFieldKnownChildSensitiveDataConsents:
+			bitSize += layout.numKnownChildSensitiveDataConsents * bitsUSField
+		default:
+			bitSize += bitsUSField
+		}
+	}
+
+	e := iabtcfv2.NewTCEncoderFromSize(bitSize)
+	e.WriteInt(s.Version, bitsVersion)
+	for _, f := range layout.fields {
+		switch f {
+		case usFieldSensitiveDataProcessing:
+			e.WriteNBitField(s.SensitiveDataProcessingCategories, layout.numSensitiveDataProcessing, bitsUSField)
+		case usFieldKnownChildSensitiveDataConsents:
+			e.WriteNBitField(s.KnownChildSensitiveDataConsents, layout.numKnownChildSensitiveDataConsents, bitsUSField)
+		default:
+			e.WriteInt(*s.field(f), bitsUSField)
+		}
+	}
+
+	value := encodeBase64(e.Bytes)
+	if s.GpcSegmentIncluded && layout.gpc {
+		g := iabtcfv2.NewTCEncoderFromSize(bitsSubsectionType + bitsGpc)
+		g.WriteInt(subsectionTypeGpc, bitsSubsectionType)
+		g.WriteBool(s.Gpc)
+		value += usSubsectionSplitter + encodeBase64(g.Bytes)
+	}
+	return value
+}
+
+// Decodes US section id
+func (g *GPPData) US(sectionId int) (*USSection, error) {
+	s := g.Section(sectionId)
+	if s == nil {
+		return nil, &DecodeError{Section: SectionName(sectionId), Err: ErrMissingSection}
+	}
+	return DecodeUSSection(sectionId, s.Value)
+}
+
+// Decodes the usnat section
+func (g *GPPData) USNat() (*USSection, error) {
+	return g.US(SectionIdUSNat)
+}
+
+// Decodes the usca section
+func (g *GPPData) USCA() (*USSection, error) {
+	return g.US(SectionIdUSCA)
+}
+
+// Decodes the usva section
+func (g *GPPData) USVA() (*USSection, error) {
+	return g.US(SectionIdUSVA)
+}
+
+// Decodes the usco section
+func (g *GPPData) USCO() (*USSection, error) {
+	return g.US(SectionIdUSCO)
+}
+
+// Decodes the usut section
+func (g *GPPData) USUT() (*USSection, error) {
+	return g.US(SectionIdUSUT)
+}
+
+// Decodes the usct section
+func (g *GPPData) USCT() (*USSection, error) {
+	return g.US(SectionIdUSCT)
+}
+
+// Sets the US section of s.SectionId
+func (g *GPPData) SetUS(s *USSection) {
+	g.SetSection(s.SectionId, s.Encode())
+}
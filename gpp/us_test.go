@@ -0,0 +1,120 @@
+package gpp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/SirDataFR/iabtcfv2"
+)
+
+func TestDecodeUSNat(t *testing.T) {
+	g, err := Decode("DBABL~BVVqAAEABCA.QA")
+	if err != nil {
+		t.Errorf("GPP String should be decoded without error: %s", err)
+		return
+	}
+
+	s, err := g.USNat()
+	if err != nil {
+		t.Errorf("Section usnat should be decoded without error: %s", err)
+		return
+	}
+
+	if s.Version != 1 || s.SharingNotice != USValueYes || s.SensitiveDataLimitUseNotice != USValueYes ||
+		s.PersonalDataConsents != USValueYes || s.MspaCoveredTransaction != USValueNotApplicable || s.MspaServiceProviderMode != USValueNo {
+		t.Errorf("Unexpected usnat section: %+v", s)
+	}
+
+	if s.IsSaleOptedOut() || s.IsSharingOptedOut() || s.IsTargetedAdvertisingOptedOut() {
+		t.Errorf("User should not have opted out")
+	}
+
+	if len(s.SensitiveDataProcessingCategories) != 12 || s.SensitiveDataProcessing(8) != USValueYes || s.SensitiveDataProcessing(7) != USValueNotApplicable || s.SensitiveDataProcessing(13) != USValueNotApplicable {
+		t.Errorf("Sensitive data category 8 should be opted out: %v", s.SensitiveDataProcessingCategories)
+	}
+
+	if !s.GpcSegmentIncluded || s.Gpc {
+		t.Errorf("GPC subsection should be decoded")
+	}
+
+	if s.Encode() != "BVVqAAEABCA.QA" {
+		t.Errorf("Section usnat should be encoded: %s", s.Encode())
+	}
+}
+
+func TestEncodeUSSections(t *testing.T) {
+	sections := []*USSection{
+		{SectionId: SectionIdUSNat, Version: 2, SaleOptOut: USValueYes, SensitiveDataProcessingCategories: make([]int, 16), KnownChildSensitiveDataConsents: []int{1, 2, 1}, GpcSegmentIncluded: true, Gpc: true},
+		{SectionId: SectionIdUSCA, Version: 1, SharingOptOut: USValueYes, SensitiveDataProcessingCategories: []int{0, 1, 2, 0, 1, 2, 0, 1, 2}, KnownChildSensitiveDataConsents: []int{2, 2}},
+		{SectionId: SectionIdUSVA, Version: 1, TargetedAdvertisingOptOut: USValueYes, SensitiveDataProcessingCategories: []int{2, 2, 2, 2, 2, 2, 2, 2}, KnownChildSensitiveDataConsents: []int{1}},
+		{SectionId: SectionIdUSCO, Version: 1, SaleOptOut: USValueNo, SensitiveDataProcessingCategories: []int{1, 1, 1, 1, 1, 1, 1}, KnownChildSensitiveDataConsents: []int{0}, GpcSegmentIncluded: true},
+		{SectionId: SectionIdUSUT, Version: 1, SensitiveDataProcessingOptOutNotice: USValueYes, SensitiveDataProcessingCategories: make([]int, 8), KnownChildSensitiveDataConsents: []int{2}},
+		{SectionId: SectionIdUSCT, Version: 1, MspaCoveredTransaction: USValueYes, SensitiveDataProcessingCategories: make([]int, 8), KnownChildSensitiveDataConsents: []int{1, 0, 2}, GpcSegmentIncluded: true, Gpc: true},
+	}
+
+	g := &GPPData{}
+	for _, s := range sections {
+		g.SetUS(s)
+	}
+
+	decoded, err := Decode(g.Encode())
+	if err != nil {
+		t.Errorf("GPP String should be decoded without error: %s", err)
+		return
+	}
+
+	for _, s := range sections {
+		d, err := decoded.US(s.SectionId)
+		if err != nil {
+			t.Errorf("Section %s should be decoded without error: %s", SectionName(s.SectionId), err)
+			continue
+		}
+		if d.Encode() != s.Encode() || d.IsSaleOptedOut() != s.IsSaleOptedOut() || d.IsSharingOptedOut() != s.IsSharingOptedOut() ||
+			d.IsTargetedAdvertisingOptedOut() != s.IsTargetedAdvertisingOptedOut() || d.Gpc != s.Gpc || d.GpcSegmentIncluded != s.GpcSegmentIncluded {
+			t.Errorf("Section %s should be decoded as encoded: %+v", SectionName(s.SectionId), d)
+		}
+		for i := range s.SensitiveDataProcessingCategories {
+			if d.SensitiveDataProcessing(i+1) != s.SensitiveDataProcessingCategories[i] {
+				t.Errorf("Section %s sensitive data category %d should be %d", SectionName(s.SectionId), i+1, s.SensitiveDataProcessingCategories[i])
+			}
+		}
+	}
+}
+
+func TestDecodeUSSectionErrors(t *testing.T) {
+	_, err := DecodeUSSection(SectionIdUSNat, "BVVq")
+	if !errors.Is(err, iabtcfv2.ErrTruncated) {
+		t.Errorf("Truncated section should return ErrTruncated: %v", err)
+	}
+
+	_, err = DecodeUSSection(SectionIdUSVA, "DVVq")
+	if !errors.Is(err, iabtcfv2.ErrUnsupportedVersion) {
+		t.Errorf("Unknown version should return ErrUnsupportedVersion: %v", err)
+	}
+
+	_, err = DecodeUSSection(SectionIdTCFEUv2, "BVVqAAEABCA")
+	if !errors.Is(err, ErrUnknownSection) {
+		t.Errorf("Non US section should return ErrUnknownSection: %v", err)
+	}
+
+	_, err = DecodeUSSection(SectionIdUSNat, "BVVqAAEABCA.gA")
+	if !errors.Is(err, ErrUnknownSection) {
+		t.Errorf("Unknown subsection should return ErrUnknownSection: %v", err)
+	}
+
+	usva := &USSection{SectionId: SectionIdUSVA, Version: 1, SensitiveDataProcessingCategories: make([]int, 8), KnownChildSensitiveDataConsents: []int{0}, GpcSegmentIncluded: true}
+	value := usva.Encode()
+	if strings.Contains(value, usSubsectionSplitter) {
+		t.Errorf("GPC subsection should not be encoded in usva: %s", value)
+	}
+	_, err = DecodeUSSection(SectionIdUSVA, value+".QA")
+	if !errors.Is(err, ErrUnknownSection) {
+		t.Errorf("GPC subsection in usva should return ErrUnknownSection: %v", err)
+	}
+
+	g, _ := Decode("DBABL~BVVqAAEABCA.QA")
+	if _, err := g.USCA(); !errors.Is(err, ErrMissingSection) {
+		t.Errorf("Missing section should return ErrMissingSection: %v", err)
+	}
+}
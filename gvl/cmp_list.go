@@ -0,0 +1,57 @@
+package gvl
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+)
+
+type CMPList struct {
+	LastUpdated time.Time    `json:"lastUpdated"`
+	CMPs        map[int]*CMP `json:"cmps"`
+}
+
+type CMP struct {
+	Id           int        `json:"id"`
+	Name         string     `json:"name"`
+	IsCommercial bool       `json:"isCommercial"`
+	Environments []string   `json:"environments,omitempty"`
+	DeletedDate  *time.Time `json:"deletedDate,omitempty"`
+}
+
+// Reads a cmp-list.json document and returns it as a CMPList structure
+func LoadCMPList(r io.Reader) (*CMPList, error) {
+	var l CMPList
+	if err := json.NewDecoder(r).Decode(&l); err != nil {
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+// Reads a cmp-list.json file and returns it as a CMPList structure
+func LoadCMPListFile(path string) (*CMPList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadCMPList(f)
+}
+
+// Returns the CMP with id, or nil if it is not in the list
+func (l *CMPList) Lookup(id int) *CMP {
+	return l.CMPs[id]
+}
+
+// Returns true if CMP was deleted from the list
+func (c *CMP) IsDeleted() bool {
+	return c.DeletedDate != nil
+}
+
+// Returns true if CMP was deleted from the list at t
+func (c *CMP) IsDeletedAt(t time.Time) bool {
+	return c.DeletedDate != nil && !t.Before(*c.DeletedDate)
+}
@@ -0,0 +1,39 @@
+package gvl
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadCMPListFile(t *testing.T) {
+	l, err := LoadCMPListFile("testdata/cmp-list.json")
+	if err != nil {
+		t.Errorf("CMP list should be loaded without error: %s", err)
+		return
+	}
+
+	c := l.Lookup(92)
+	if c == nil || c.Name != "Sirdata" || !c.IsCommercial || len(c.Environments) != 2 || c.IsDeleted() {
+		t.Errorf("Unexpected CMP 92: %+v", c)
+	}
+
+	c = l.Lookup(300)
+	if c == nil || c.IsCommercial || !c.IsDeleted() {
+		t.Errorf("CMP 300 should be deleted: %+v", c)
+		return
+	}
+	if c.IsDeletedAt(time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)) || !c.IsDeletedAt(time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("CMP 300 should be deleted since 2022-01-01")
+	}
+
+	if l.Lookup(1) != nil {
+		t.Errorf("CMP 1 should not be in the list")
+	}
+}
+
+func TestLoadCMPListInvalid(t *testing.T) {
+	if _, err := LoadCMPList(strings.NewReader(`{"cmps": []}`)); err == nil {
+		t.Errorf("Malformed CMP list should return an error")
+	}
+}
@@ -0,0 +1,201 @@
+// Package gvl reads the IAB Global Vendor List (vendor-list.json) and CMP list (cmp-list.json).
+//
+// Both the v2 (TCF 2.0 / 2.1) and v3 (TCF 2.2) schemas of the vendor list are supported.
+package gvl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	SpecificationVersion2 = 2
+	SpecificationVersion3 = 3
+)
+
+type VendorList struct {
+	GvlSpecificationVersion int                     `json:"gvlSpecificationVersion"`
+	VendorListVersion       int                     `json:"vendorListVersion"`
+	TcfPolicyVersion        int                     `json:"tcfPolicyVersion"`
+	LastUpdated             time.Time               `json:"lastUpdated"`
+	Purposes                map[int]*Purpose        `json:"purposes"`
+	SpecialPurposes         map[int]*SpecialPurpose `json:"specialPurposes"`
+	Features                map[int]*Feature        `json:"features"`
+	SpecialFeatures         map[int]*SpecialFeature `json:"specialFeatures"`
+	Stacks                  map[int]*Stack          `json:"stacks"`
+	DataCategories          map[int]*DataCategory   `json:"dataCategories,omitempty"`
+	Vendors                 map[int]*Vendor         `json:"vendors"`
+}
+
+type Purpose struct {
+	Id               int      `json:"id"`
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	DescriptionLegal string   `json:"descriptionLegal,omitempty"`
+	Illustrations    []string `json:"illustrations,omitempty"`
+}
+
+type SpecialPurpose Purpose
+
+type Feature Purpose
+
+type SpecialFeature Purpose
+
+type Stack struct {
+	Id              int    `json:"id"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	Purposes        []int  `json:"purposes"`
+	SpecialFeatures []int  `json:"specialFeatures"`
+}
+
+type DataCategory struct {
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type Vendor struct {
+	Id                         int            `json:"id"`
+	Name                       string         `json:"name"`
+	Purposes                   []int          `json:"purposes"`
+	LegIntPurposes             []int          `json:"legIntPurposes"`
+	FlexiblePurposes           []int          `json:"flexiblePurposes"`
+	SpecialPurposes            []int          `json:"specialPurposes"`
+	Features                   []int          `json:"features"`
+	SpecialFeatures            []int          `json:"specialFeatures"`
+	PolicyUrl                  string         `json:"policyUrl,omitempty"`
+	Urls                       []*VendorUrl   `json:"urls,omitempty"`
+	DeletedDate                *time.Time     `json:"deletedDate,omitempty"`
+	Overflow                   *Overflow      `json:"overflow,omitempty"`
+	CookieMaxAgeSeconds        int64          `json:"cookieMaxAgeSeconds"`
+	UsesCookies                bool           `json:"usesCookies"`
+	CookieRefresh              bool           `json:"cookieRefresh"`
+	UsesNonCookieAccess        bool           `json:"usesNonCookieAccess"`
+	DeviceStorageDisclosureUrl string         `json:"deviceStorageDisclosureUrl,omitempty"`
+	DataRetention              *DataRetention `json:"dataRetention,omitempty"`
+	DataDeclaration            []int          `json:"dataDeclaration,omitempty"`
+}
+
+type VendorUrl struct {
+	LangId      string `json:"langId"`
+	Privacy     string `json:"privacy"`
+	LegIntClaim string `json:"legIntClaim,omitempty"`
+}
+
+type Overflow struct {
+	HttpGetLimit int `json:"httpGetLimit"`
+}
+
+type DataRetention struct {
+	StdRetention    int         `json:"stdRetention"`
+	Purposes        map[int]int `json:"purposes"`
+	SpecialPurposes map[int]int `json:"specialPurposes"`
+}
+
+// Reads a vendor-list.json document and returns it as a VendorList structure
+func Load(r io.Reader) (*VendorList, error) {
+	var vl VendorList
+	if err := json.NewDecoder(r).Decode(&vl); err != nil {
+		return nil, err
+	}
+
+	if vl.GvlSpecificationVersion != SpecificationVersion2 && vl.GvlSpecificationVersion != SpecificationVersion3 {
+		return nil, fmt.Errorf("unsupported gvl specification version %d", vl.GvlSpecificationVersion)
+	}
+
+	return &vl, nil
+}
+
+// Reads a vendor-list.json file and returns it as a VendorList structure
+func LoadFile(path string) (*VendorList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// Returns the vendor with id, or nil if it is not in the list
+func (l *VendorList) Vendor(id int) *Vendor {
+	return l.Vendors[id]
+}
+
+// Returns the purpose with id, or nil if it is not in the list
+func (l *VendorList) Purpose(id int) *Purpose {
+	return l.Purposes[id]
+}
+
+// Returns the special purpose with id, or nil if it is not in the list
+func (l *VendorList) SpecialPurpose(id int) *SpecialPurpose {
+	return l.SpecialPurposes[id]
+}
+
+// Returns the feature with id, or nil if it is not in the list
+func (l *VendorList) Feature(id int) *Feature {
+	return l.Features[id]
+}
+
+// Returns the special feature with id, or nil if it is not in the list
+func (l *VendorList) SpecialFeature(id int) *SpecialFeature {
+	return l.SpecialFeatures[id]
+}
+
+// Returns the stack with id, or nil if it is not in the list
+func (l *VendorList) Stack(id int) *Stack {
+	return l.Stacks[id]
+}
+
+// Returns the data category with id, or nil if it is not in the list
+func (l *VendorList) DataCategory(id int) *DataCategory {
+	return l.DataCategories[id]
+}
+
+// Returns true if vendor was deleted from the list
+func (v *Vendor) IsDeleted() bool {
+	return v.DeletedDate != nil
+}
+
+// Returns true if vendor declares purpose id under consent
+func (v *Vendor) HasPurpose(id int) bool {
+	return contains(v.Purposes, id)
+}
+
+// Returns true if vendor declares purpose id under legitimate interest
+func (v *Vendor) HasLegIntPurpose(id int) bool {
+	return contains(v.LegIntPurposes, id)
+}
+
+// Returns true if vendor declares purpose id as flexible
+func (v *Vendor) IsFlexiblePurpose(id int) bool {
+	return contains(v.FlexiblePurposes, id)
+}
+
+// Returns true if vendor declares special purpose id
+func (v *Vendor) HasSpecialPurpose(id int) bool {
+	return contains(v.SpecialPurposes, id)
+}
+
+// Returns true if vendor declares feature id
+func (v *Vendor) HasFeature(id int) bool {
+	return contains(v.Features, id)
+}
+
+// Returns true if vendor declares special feature id
+func (v *Vendor) HasSpecialFeature(id int) bool {
+	return contains(v.SpecialFeatures, id)
+}
+
+func contains(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
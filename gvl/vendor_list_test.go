@@ -0,0 +1,81 @@
+package gvl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadFileV2(t *testing.T) {
+	vl, err := LoadFile("testdata/vendor-list-v2.json")
+	if err != nil {
+		t.Errorf("Vendor list should be loaded without error: %s", err)
+		return
+	}
+
+	if vl.VendorListVersion != 48 {
+		t.Errorf("VendorListVersion should be 48")
+	}
+
+	if vl.Purpose(1) == nil || vl.Purpose(1).DescriptionLegal == "" {
+		t.Errorf("Purpose 1 should have a legal description")
+	}
+
+	v := vl.Vendor(8)
+	if v == nil {
+		t.Errorf("Vendor 8 should be in the list")
+		return
+	}
+
+	if v.PolicyUrl == "" || v.Overflow == nil || v.Overflow.HttpGetLimit != 32 {
+		t.Errorf("Vendor 8 should have a policy url and an overflow")
+	}
+}
+
+func TestLoadFileV3(t *testing.T) {
+	vl, err := LoadFile("testdata/vendor-list-v3.json")
+	if err != nil {
+		t.Errorf("Vendor list should be loaded without error: %s", err)
+		return
+	}
+
+	if vl.GvlSpecificationVersion != SpecificationVersion3 || vl.TcfPolicyVersion != 4 {
+		t.Errorf("Vendor list should use specification version 3 and policy version 4")
+	}
+
+	if vl.DataCategory(1) == nil || vl.Stack(1) == nil || vl.SpecialPurpose(2) == nil || vl.Feature(2) == nil || vl.SpecialFeature(1) == nil {
+		t.Errorf("Vendor list lookups should find declared entries")
+	}
+
+	v := vl.Vendor(1)
+	if v == nil {
+		t.Errorf("Vendor 1 should be in the list")
+		return
+	}
+
+	if !v.HasPurpose(3) || !v.HasLegIntPurpose(2) || !v.IsFlexiblePurpose(7) || !v.HasSpecialPurpose(2) || !v.HasFeature(1) || !v.HasSpecialFeature(1) {
+		t.Errorf("Vendor 1 declarations should be decoded")
+	}
+
+	if v.HasPurpose(2) {
+		t.Errorf("Vendor 1 should not declare purpose 2 under consent")
+	}
+
+	if len(v.Urls) != 1 || v.DataRetention == nil || v.DataRetention.Purposes[1] != 30 {
+		t.Errorf("Vendor 1 urls and data retention should be decoded")
+	}
+
+	if !vl.Vendor(4).IsDeleted() || vl.Vendor(1).IsDeleted() {
+		t.Errorf("Only vendor 4 should be deleted")
+	}
+
+	if vl.Vendor(999) != nil {
+		t.Errorf("Vendor 999 should not be in the list")
+	}
+}
+
+func TestLoadUnsupportedVersion(t *testing.T) {
+	_, err := Load(strings.NewReader(`{"gvlSpecificationVersion": 1, "vendorListVersion": 1}`))
+	if err == nil {
+		t.Errorf("Vendor list with specification version 1 should not be loaded")
+	}
+}
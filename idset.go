@@ -0,0 +1,251 @@
+package iabtcfv2
+
+import (
+	"encoding/json"
+	"math/bits"
+)
+
+// IDSet is a set of positive ids backed by a bit set
+// The zero value is an empty set ready to use, and a nil *IDSet is a read-only empty set
+type IDSet struct {
+	words []uint64
+}
+
+// Returns a set containing ids
+func NewIDSet(ids ...int) *IDSet {
+	s := &IDSet{}
+	for _, id := range ids {
+		s.Add(id)
+	}
+	return s
+}
+
+// Returns a set containing the ids of a bit field or range entries
+func newIDSetFromEncoding(isRangeEncoding bool, bitField map[int]bool, entries []*RangeEntry) *IDSet {
+	s := &IDSet{}
+	if isRangeEncoding {
+		max := 0
+		for _, entry := range entries {
+			if entry.EndVendorID > max {
+				max = entry.EndVendorID
+			}
+		}
+		s.grow(max)
+		for _, entry := range entries {
+			s.addRange(entry.StartVendorID, entry.EndVendorID)
+		}
+		return s
+	}
+
+	max := 0
+	for id, v := range bitField {
+		if v && id > max {
+			max = id
+		}
+	}
+	s.grow(max)
+	for id, v := range bitField {
+		if v {
+			s.Add(id)
+		}
+	}
+	return s
+}
+
+// Returns true if set contains id
+func (s *IDSet) Contains(id int) bool {
+	if s == nil || id < 1 {
+		return false
+	}
+
+	i := id / 64
+	return i < len(s.words) && s.words[i]&(1<<uint(id%64)) != 0
+}
+
+// Adds id to the set
+// Ids lower than 1 are ignored
+func (s *IDSet) Add(id int) {
+	if id < 1 {
+		return
+	}
+
+	s.grow(id)
+	s.words[id/64] |= 1 << uint(id%64)
+}
+
+// Adds ids from start to end to the set, a whole word at a time
+func (s *IDSet) addRange(start int, end int) {
+	if start < 1 {
+		start = 1
+	}
+	if end < start {
+		return
+	}
+
+	s.grow(end)
+	for i := start / 64; i <= end/64; i++ {
+		lo, hi := uint(0), uint(63)
+		if i == start/64 {
+			lo = uint(start % 64)
+		}
+		if i == end/64 {
+			hi = uint(end % 64)
+		}
+		s.words[i] |= (^uint64(0) >> (63 - hi)) &^ (1<<lo - 1)
+	}
+}
+
+// Makes room for ids up to max, growing the words like append to amortize successive additions
+func (s *IDSet) grow(max int) {
+	if n := max/64 + 1; n > len(s.words) {
+		s.words = append(s.words, make([]uint64, n-len(s.words))...)
+	}
+}
+
+// Removes id from the set
+func (s *IDSet) Remove(id int) {
+	if id < 1 {
+		return
+	}
+
+	i := id / 64
+	if i < len(s.words) {
+		s.words[i] &^= 1 << uint(id%64)
+	}
+}
+
+// Calls f for each id of the set in ascending order until f returns false
+func (s *IDSet) Iterate(f func(id int) bool) {
+	if s == nil {
+		return
+	}
+
+	for i, w := range s.words {
+		for w != 0 {
+			b := bits.TrailingZeros64(w)
+			if !f(i*64 + b) {
+				return
+			}
+			w &^= 1 << uint(b)
+		}
+	}
+}
+
+// Returns the number of ids in the set
+func (s *IDSet) Len() int {
+	if s == nil {
+		return 0
+	}
+
+	n := 0
+	for _, w := range s.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// Returns the highest id of the set, or 0 if the set is empty
+func (s *IDSet) Max() int {
+	if s == nil {
+		return 0
+	}
+
+	for i := len(s.words) - 1; i >= 0; i-- {
+		if s.words[i] != 0 {
+			return i*64 + 63 - bits.LeadingZeros64(s.words[i])
+		}
+	}
+	return 0
+}
+
+// Returns the ids of the set in ascending order
+func (s *IDSet) IDs() []int {
+	ids := make([]int, 0, s.Len())
+	s.Iterate(func(id int) bool {
+		ids = append(ids, id)
+		return true
+	})
+	return ids
+}
+
+// Returns a new set containing the ids of s that aren't in o
+func (s *IDSet) difference(o *IDSet) *IDSet {
+	a, b := s.wordsOrNil(), o.wordsOrNil()
+	words := make([]uint64, len(a))
+	for i, w := range a {
+		if i < len(b) {
+			w &^= b[i]
+		}
+		words[i] = w
+	}
+	return &IDSet{words: words}
+}
+
+// Returns a new set containing the ids of both sets
+func (s *IDSet) Union(o *IDSet) *IDSet {
+	a, b := s.wordsOrNil(), o.wordsOrNil()
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+
+	words := make([]uint64, len(a))
+	copy(words, a)
+	for i, w := range b {
+		words[i] |= w
+	}
+	return &IDSet{words: words}
+}
+
+// Returns a new set containing the ids present in both sets
+func (s *IDSet) Intersect(o *IDSet) *IDSet {
+	a, b := s.wordsOrNil(), o.wordsOrNil()
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	words := make([]uint64, len(a))
+	for i, w := range a {
+		words[i] = w & b[i]
+	}
+	return &IDSet{words: words}
+}
+
+// Returns the ids of the set as sorted range entries, with consecutive ids coalesced
+func (s *IDSet) Ranges() []*RangeEntry {
+	var entries []*RangeEntry
+	s.Iterate(func(id int) bool {
+		if n := len(entries); n > 0 && entries[n-1].EndVendorID+1 == id {
+			entries[n-1].EndVendorID = id
+		} else {
+			entries = append(entries, &RangeEntry{StartVendorID: id, EndVendorID: id})
+		}
+		return true
+	})
+	return entries
+}
+
+// Marshals the set as a sorted array of ids
+func (s *IDSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.IDs())
+}
+
+// Unmarshals the set from an array of ids
+func (s *IDSet) UnmarshalJSON(b []byte) error {
+	var ids []int
+	if err := json.Unmarshal(b, &ids); err != nil {
+		return err
+	}
+
+	*s = IDSet{}
+	for _, id := range ids {
+		s.Add(id)
+	}
+	return nil
+}
+
+func (s *IDSet) wordsOrNil() []uint64 {
+	if s == nil {
+		return nil
+	}
+	return s.words
+}
@@ -0,0 +1,112 @@
+package iabtcfv2
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestIDSet(t *testing.T) {
+	s := NewIDSet(3, 1, 2, 64, 65, 200, 0, -1)
+
+	if s.Len() != 6 || s.Max() != 200 {
+		t.Errorf("Set should contain 6 ids up to 200: %v", s.IDs())
+		return
+	}
+
+	if !s.Contains(64) || s.Contains(4) || s.Contains(0) || s.Contains(1000) {
+		t.Errorf("Set should only contain added ids")
+	}
+
+	s.Remove(200)
+	s.Remove(1000)
+	if s.Contains(200) || s.Max() != 65 {
+		t.Errorf("Id 200 should be removed")
+	}
+
+	ranges := s.Ranges()
+	if len(ranges) != 2 || ranges[0].StartVendorID != 1 || ranges[0].EndVendorID != 3 || ranges[1].StartVendorID != 64 || ranges[1].EndVendorID != 65 {
+		t.Errorf("Ranges() should coalesce consecutive ids")
+	}
+
+	var ids []int
+	s.Iterate(func(id int) bool {
+		ids = append(ids, id)
+		return id < 3
+	})
+	if len(ids) != 3 || ids[2] != 3 {
+		t.Errorf("Iterate() should stop when f returns false: %v", ids)
+	}
+
+	o := NewIDSet(2, 65, 300)
+	if u := s.Union(o); u.Len() != 6 || !u.Contains(300) {
+		t.Errorf("Union() should contain ids of both sets: %v", u.IDs())
+	}
+	if i := s.Intersect(o); i.Len() != 2 || !i.Contains(2) || !i.Contains(65) {
+		t.Errorf("Intersect() should contain ids present in both sets: %v", i.IDs())
+	}
+
+	var empty *IDSet
+	if empty.Contains(1) || empty.Len() != 0 || len(empty.Union(o).IDs()) != 3 {
+		t.Errorf("Nil set should be empty")
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil || string(b) != "[1,2,3,64,65]" {
+		t.Errorf("Set should be marshaled as a sorted array: %s", b)
+		return
+	}
+
+	var u IDSet
+	if err := json.Unmarshal(b, &u); err != nil || u.Len() != 5 || !u.Contains(64) {
+		t.Errorf("Set should be unmarshaled from an array: %v", err)
+	}
+}
+
+func TestDecodeIDSets(t *testing.T) {
+	str := "COxR03kOxR1CqBcABCENAgCMAP_AAH_AAAqIF3EXySoGY2thI2YVFxBEIYwfJxyigMgChgQIsSwNQIeFLBoGLiAAHBGYJAQAGBAEEACBAQIkHGBMCQAAgAgBiRCMQEGMCzNIBIBAggEbY0FACCVmHkHSmZCY7064O__QLuIJEFQMAkSBAIACLECIQwAQDiAAAYAlAAABAhIaAAgIWBQEeAAAACAwAAgAAABBAAACAAQAAICIAAABAAAgAiAQAAAAGgIQAACBABACRIAAAEANCAAgiCEAQg4EAo4AAA.IDaQBQAMgAgABqAR0A2g"
+
+	data, err := Decode(str)
+	if err != nil {
+		t.Errorf("TC String should be decoded without error: %s", err)
+		return
+	}
+
+	for id := 1; id <= 800; id++ {
+		if data.CoreString.VendorsConsentSet.Contains(id) != data.IsVendorAllowed(id) ||
+			data.CoreString.VendorsLITransparencySet.Contains(id) != data.IsVendorLIAllowed(id) ||
+			data.DisclosedVendors.DisclosedVendorsSet.Contains(id) != data.DisclosedVendors.IsVendorDisclosed(id) {
+			t.Errorf("Vendor %d should be the same in sets and segments", id)
+			return
+		}
+	}
+
+	for _, r := range data.CoreString.PubRestrictions {
+		if r.VendorSet.Len() == 0 {
+			t.Errorf("Publisher restriction on purpose %d should have vendors", r.PurposeId)
+		}
+	}
+}
+
+func TestIDSetFromRangeEntries(t *testing.T) {
+	entries := []*RangeEntry{{StartVendorID: 0, EndVendorID: 5}, {StartVendorID: 60, EndVendorID: 130}, {StartVendorID: 192, EndVendorID: 255}, {StartVendorID: 300, EndVendorID: 299}}
+	s := newIDSetFromEncoding(true, nil, entries)
+
+	expected := NewIDSet()
+	for _, entry := range entries {
+		for id := entry.StartVendorID; id <= entry.EndVendorID; id++ {
+			expected.Add(id)
+		}
+	}
+	if s.Len() != expected.Len() || s.Intersect(expected).Len() != expected.Len() {
+		t.Errorf("Range entries should be added word by word: %v", s.Ranges())
+	}
+
+	// A set of range entries covering all vendor ids many times is built without adding ids one by one
+	entries = make([]*RangeEntry, 1000)
+	for i := range entries {
+		entries[i] = &RangeEntry{StartVendorID: 1, EndVendorID: 1<<bitsVendorId - 1}
+	}
+	if s := newIDSetFromEncoding(true, nil, entries); s.Len() != 1<<bitsVendorId-1 {
+		t.Errorf("Set should contain all vendor ids: %d", s.Len())
+	}
+}
@@ -0,0 +1,114 @@
+package iabtcfv2
+
+// PolicyRules are the TCF policy rules the legal basis checks apply
+// They are selected from TcfPolicyVersion, and can be overridden with the PolicyRules field of a CoreStringView,
+// Enforcer or ValidationOptions, or with WithPolicyRules on a CoreString or TCData
+type PolicyRules struct {
+	// Purposes that can never be processed under legitimate interest
+	NoLegitimateInterestPurposes []int
+}
+
+var (
+	// Rules of policy versions 2 and 3 (TCF 2.0 and 2.1)
+	policyRulesV2 = &PolicyRules{
+		NoLegitimateInterestPurposes: []int{1},
+	}
+	// Rules of policy versions 4 and 5 (TCF 2.2), where purposes 3 to 6 require consent
+	policyRulesV4 = &PolicyRules{
+		NoLegitimateInterestPurposes: []int{1, 3, 4, 5, 6},
+	}
+)
+
+// Returns a copy of the rules of TCF policy version, that can be changed and set as an override
+// Versions before 4 only exclude purpose 1 from legitimate interest, later ones also exclude purposes 3 to 6
+func PolicyRulesForVersion(version int) *PolicyRules {
+	r := policyRulesForVersion(version)
+	return &PolicyRules{NoLegitimateInterestPurposes: append([]int(nil), r.NoLegitimateInterestPurposes...)}
+}
+
+// Returns the shared rules of TCF policy version, which must not be changed
+func policyRulesForVersion(version int) *PolicyRules {
+	if version < 4 {
+		return policyRulesV2
+	}
+	return policyRulesV4
+}
+
+// Returns true if purpose id can be processed under legitimate interest
+func (r *PolicyRules) AllowsLegitimateInterest(purposeId int) bool {
+	for _, id := range r.NoLegitimateInterestPurposes {
+		if id == purposeId {
+			return false
+		}
+	}
+	return true
+}
+
+// PolicyOverride answers the legal basis checks of a CoreString with PolicyRules instead of the rules of its TcfPolicyVersion
+// The CoreString isn't changed, so the same one can be checked with different rules
+type PolicyOverride struct {
+	CoreString  *CoreString
+	PolicyRules *PolicyRules
+}
+
+// Returns the legal basis checks of c applying rules, or the rules of TcfPolicyVersion when nil
+func (c *CoreString) WithPolicyRules(rules *PolicyRules) *PolicyOverride {
+	return &PolicyOverride{CoreString: c, PolicyRules: rules}
+}
+
+// Returns the legal basis checks of the Core String of t applying rules, or the rules of TcfPolicyVersion when nil
+func (t *TCData) WithPolicyRules(rules *PolicyRules) *PolicyOverride {
+	return t.CoreString.WithPolicyRules(rules)
+}
+
+// Returns true if user has given consent to vendor id processing all purposes ids
+// and publisher hasn't set restrictions for them
+func (o *PolicyOverride) IsVendorAllowedForPurposes(id int, purposeIds ...int) bool {
+	return o.CoreString.vendorAllowedForPurposes(nil, o.PolicyRules, id, purposeIds, LegalBasisConsent)
+}
+
+// Returns true if transparency for vendor id's legitimate interest is established for all purpose ids
+// and publisher hasn't set restrictions for them
+func (o *PolicyOverride) IsVendorAllowedForPurposesLI(id int, purposeIds ...int) bool {
+	return o.CoreString.vendorAllowedForPurposes(nil, o.PolicyRules, id, purposeIds, LegalBasisLegitimateInterest)
+}
+
+// Returns true if user has given consent to vendor id processing all purposes ids
+// or if transparency for its legitimate interest is established in accordance with publisher restrictions
+func (o *PolicyOverride) IsVendorAllowedForFlexiblePurposes(id int, purposeIds ...int) bool {
+	return o.CoreString.vendorAllowedForFlexiblePurposes(nil, o.PolicyRules, id, purposeIds, LegalBasisConsent)
+}
+
+// Returns true if transparency for vendor id's legitimate interest is established for all purpose ids
+// or if user has given consent in accordance with publisher restrictions
+func (o *PolicyOverride) IsVendorAllowedForFlexiblePurposesLI(id int, purposeIds ...int) bool {
+	return o.CoreString.vendorAllowedForFlexiblePurposes(nil, o.PolicyRules, id, purposeIds, LegalBasisLegitimateInterest)
+}
+
+// Returns the Decision of IsVendorAllowedForPurposes
+func (o *PolicyOverride) ExplainVendorAllowedForPurposes(id int, purposeIds ...int) *Decision {
+	d := &Decision{}
+	o.CoreString.vendorAllowedForPurposes(d, o.PolicyRules, id, purposeIds, LegalBasisConsent)
+	return d
+}
+
+// Returns the Decision of IsVendorAllowedForPurposesLI
+func (o *PolicyOverride) ExplainVendorAllowedForPurposesLI(id int, purposeIds ...int) *Decision {
+	d := &Decision{}
+	o.CoreString.vendorAllowedForPurposes(d, o.PolicyRules, id, purposeIds, LegalBasisLegitimateInterest)
+	return d
+}
+
+// Returns the Decision of IsVendorAllowedForFlexiblePurposes
+func (o *PolicyOverride) ExplainVendorAllowedForFlexiblePurposes(id int, purposeIds ...int) *Decision {
+	d := &Decision{}
+	o.CoreString.vendorAllowedForFlexiblePurposes(d, o.PolicyRules, id, purposeIds, LegalBasisConsent)
+	return d
+}
+
+// Returns the Decision of IsVendorAllowedForFlexiblePurposesLI
+func (o *PolicyOverride) ExplainVendorAllowedForFlexiblePurposesLI(id int, purposeIds ...int) *Decision {
+	d := &Decision{}
+	o.CoreString.vendorAllowedForFlexiblePurposes(d, o.PolicyRules, id, purposeIds, LegalBasisLegitimateInterest)
+	return d
+}
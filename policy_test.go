@@ -0,0 +1,99 @@
+package iabtcfv2
+
+import (
+	"testing"
+
+	"github.com/SirDataFR/iabtcfv2/gvl"
+)
+
+func TestPolicyRules(t *testing.T) {
+	c := &CoreString{
+		Version:                int(TcfVersion2),
+		Created:                timeFromDeciSeconds(16431552000),
+		LastUpdated:            timeFromDeciSeconds(16431552000),
+		ConsentLanguage:        "EN",
+		PublisherCC:            "FR",
+		TcfPolicyVersion:       2,
+		PurposesConsent:        map[int]bool{1: true},
+		PurposesLITransparency: map[int]bool{2: true, 3: true},
+		NumPubRestrictions:     1,
+		PubRestrictions: []*PubRestriction{
+			{PurposeId: 3, RestrictionType: RestrictionTypeRequireLI, NumEntries: 1, RangeEntries: []*RangeEntry{{StartVendorID: 1, EndVendorID: 1}}},
+		},
+	}
+	c.SetVendorsConsent(1)
+	c.SetVendorsLITransparency(1)
+
+	if !c.IsVendorAllowedForPurposesLI(1, 2, 3) || !c.IsVendorAllowedForFlexiblePurposes(1, 3) {
+		t.Errorf("Vendor 1 should process purpose 3 under legitimate interest with policy version 2")
+	}
+
+	c.TcfPolicyVersion = 4
+	if c.IsVendorAllowedForPurposesLI(1, 2, 3) || c.IsVendorAllowedForFlexiblePurposes(1, 3) {
+		t.Errorf("Vendor 1 should not process purpose 3 under legitimate interest with policy version 4")
+	}
+	if !c.IsVendorAllowedForPurposesLI(1, 2) {
+		t.Errorf("Vendor 1 should process purpose 2 under legitimate interest with policy version 4")
+	}
+
+	d := c.ExplainVendorAllowedForFlexiblePurposes(1, 3)
+	if d.Allowed || d.Failed == nil || d.Failed.Field != fieldPolicyNoLegitimateInterestPurposes || d.Failed.Id != 3 {
+		t.Errorf("Decision should fail on the policy rules: %+v", d.Failed)
+	}
+
+	v, err := NewCoreStringView(c.Encode())
+	if err != nil {
+		t.Fatalf("View should be created without error: %s", err)
+	}
+	if v.IsVendorAllowedForFlexiblePurposes(1, 3) {
+		t.Errorf("View should not allow purpose 3 under legitimate interest with policy version 4")
+	}
+
+	rules := &PolicyRules{NoLegitimateInterestPurposes: []int{1}}
+	v.PolicyRules = rules
+	data := &TCData{CoreString: c}
+	if !v.IsVendorAllowedForFlexiblePurposes(1, 3) || !data.WithPolicyRules(rules).IsVendorAllowedForFlexiblePurposes(1, 3) ||
+		!data.WithPolicyRules(rules).ExplainVendorAllowedForPurposesLI(1, 2, 3).Allowed {
+		t.Errorf("Overridden policy rules should allow purpose 3 under legitimate interest")
+	}
+	if data.IsVendorAllowedForFlexiblePurposes(1, 3) || data.WithPolicyRules(nil).IsVendorAllowedForFlexiblePurposes(1, 3) {
+		t.Errorf("Policy rules should only be overridden by WithPolicyRules")
+	}
+
+	opts := ValidationOptions{Now: timeFromDeciSeconds(16431552000)}
+	if !hasValidationCode(Validate(data, opts), ValidationCodeLegitimateInterestPurpose) {
+		t.Errorf("Purpose 3 under legitimate interest should be reported with policy version 4")
+	}
+	opts.PolicyRules = rules
+	if hasValidationCode(Validate(data, opts), ValidationCodeLegitimateInterestPurpose) {
+		t.Errorf("Purpose 3 under legitimate interest should not be reported with overridden policy rules")
+	}
+
+	vl, err := gvl.LoadFile("gvl/testdata/vendor-list-v3.json")
+	if err != nil {
+		t.Fatalf("Vendor list should be loaded without error: %s", err)
+	}
+	e := NewEnforcer(data, vl)
+	if e.LegalBasis(1, 2) != LegalBasisLegitimateInterest {
+		t.Errorf("Vendor 1 should process purpose 2 under legitimate interest")
+	}
+	e.PolicyRules = &PolicyRules{NoLegitimateInterestPurposes: []int{1, 2}}
+	if e.LegalBasis(1, 2) != LegalBasisNone {
+		t.Errorf("Overridden policy rules should not allow purpose 2 under legitimate interest")
+	}
+
+	copied := PolicyRulesForVersion(4)
+	copied.NoLegitimateInterestPurposes[0] = 2
+	if PolicyRulesForVersion(4).NoLegitimateInterestPurposes[0] != 1 {
+		t.Errorf("Changing returned policy rules should not change the rules of the version")
+	}
+}
+
+func hasValidationCode(issues []*ValidationIssue, code ValidationCode) bool {
+	for _, i := range issues {
+		if i.Code == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package iabtcfv2
+
+import (
+	"encoding/base64"
+)
+
+type AllowedVendors struct {
+	SegmentType       int
+	MaxVendorId       int
+	IsRangeEncoding   bool
+	AllowedVendors    map[int]bool
+	AllowedVendorsSet *IDSet
+	NumEntries        int
+	RangeEntries      []*RangeEntry
+}
+
+// Returns true if vendor id is allowed by publisher to use OOB signaling
+func (a *AllowedVendors) IsVendorAllowed(id int) bool {
+	if a.IsRangeEncoding {
+		for _, entry := range a.RangeEntries {
+			if entry.StartVendorID <= id && id <= entry.EndVendorID {
+				return true
+			}
+		}
+		return false
+	}
+
+	return a.AllowedVendors[id]
+}
+
+// Sets the allowed vendors with the shortest encoding
+// MaxVendorId, IsRangeEncoding, AllowedVendors, NumEntries, RangeEntries and AllowedVendorsSet are derived from ids
+func (a *AllowedVendors) SetAllowedVendors(ids ...int) {
+	set := NewIDSet(ids...)
+	e := newVendorEncoding(set)
+	a.MaxVendorId = e.maxVendorId
+	a.IsRangeEncoding = e.isRangeEncoding
+	a.AllowedVendors = e.bitField
+	a.NumEntries = len(e.rangeEntries)
+	a.RangeEntries = e.rangeEntries
+	a.AllowedVendorsSet = set
+}
+
+// Re-encodes allowed vendors with the shortest encoding
+func (a *AllowedVendors) OptimizeVendorEncoding() {
+	a.SetAllowedVendors(newIDSetFromEncoding(a.IsRangeEncoding, a.AllowedVendors, a.RangeEntries).IDs()...)
+}
+
+// Returns structure as a base64 raw url encoded string
+func (a *AllowedVendors) Encode() string {
+	var bitSize int
+	bitSize += bitsSegmentType
+
+	bitSize += bitsMaxVendorId
+	bitSize += bitsIsRangeEncoding
+	if a.IsRangeEncoding {
+		bitSize += bitsNumEntries
+		for _, entry := range a.RangeEntries {
+			bitSize += entry.getBitSize()
+		}
+	} else {
+		if a.MaxVendorId == 0 {
+			for id, _ := range a.AllowedVendors {
+				if id > a.MaxVendorId {
+					a.MaxVendorId = id
+				}
+			}
+		}
+		bitSize += a.MaxVendorId
+	}
+
+	e := NewTCEncoderFromSize(bitSize)
+	e.WriteInt(a.SegmentType, bitsSegmentType)
+	e.WriteInt(a.MaxVendorId, bitsMaxVendorId)
+	e.WriteBool(a.IsRangeEncoding)
+	if a.IsRangeEncoding {
+		e.WriteRangeEntries(a.RangeEntries)
+	} else {
+		e.WriteBools(a.IsVendorAllowed, a.MaxVendorId)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(e.Bytes)
+}
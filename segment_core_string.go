@@ -6,34 +6,36 @@ import (
 )
 
 type CoreString struct {
-	Version                int
-	Created                time.Time
-	LastUpdated            time.Time
-	CmpId                  int
-	CmpVersion             int
-	ConsentScreen          int
-	ConsentLanguage        string
-	VendorListVersion      int
-	TcfPolicyVersion       int
-	IsServiceSpecific      bool
-	UseNonStandardTexts    bool
-	SpecialFeatureOptIns   map[int]bool
-	PurposesConsent        map[int]bool
-	PurposesLITransparency map[int]bool
-	PurposeOneTreatment    bool
-	PublisherCC            string
-	MaxVendorId            int
-	IsRangeEncoding        bool
-	VendorsConsent         map[int]bool
-	NumEntries             int
-	RangeEntries           []*RangeEntry
-	MaxVendorIdLI          int
-	IsRangeEncodingLI      bool
-	VendorsLITransparency  map[int]bool
-	NumEntriesLI           int
-	RangeEntriesLI         []*RangeEntry
-	NumPubRestrictions     int
-	PubRestrictions        []*PubRestriction
+	Version                  int
+	Created                  time.Time
+	LastUpdated              time.Time
+	CmpId                    int
+	CmpVersion               int
+	ConsentScreen            int
+	ConsentLanguage          string
+	VendorListVersion        int
+	TcfPolicyVersion         int
+	IsServiceSpecific        bool
+	UseNonStandardTexts      bool
+	SpecialFeatureOptIns     map[int]bool
+	PurposesConsent          map[int]bool
+	PurposesLITransparency   map[int]bool
+	PurposeOneTreatment      bool
+	PublisherCC              string
+	MaxVendorId              int
+	IsRangeEncoding          bool
+	VendorsConsent           map[int]bool
+	VendorsConsentSet        *IDSet
+	NumEntries               int
+	RangeEntries             []*RangeEntry
+	MaxVendorIdLI            int
+	IsRangeEncodingLI        bool
+	VendorsLITransparency    map[int]bool
+	VendorsLITransparencySet *IDSet
+	NumEntriesLI             int
+	RangeEntriesLI           []*RangeEntry
+	NumPubRestrictions       int
+	PubRestrictions          []*PubRestriction
 }
 
 type PubRestriction struct {
@@ -41,6 +43,7 @@ type PubRestriction struct {
 	RestrictionType RestrictionType
 	NumEntries      int
 	RangeEntries    []*RangeEntry
+	VendorSet       *IDSet
 }
 
 func (r *PubRestriction) getBitSize() (bitSize int) {
@@ -116,129 +119,228 @@ func (c *CoreString) IsVendorLIAllowed(id int) bool {
 // Returns true if user has given consent to vendor id processing all purposes ids
 // and publisher hasn't set restrictions for them
 func (c *CoreString) IsVendorAllowedForPurposes(id int, purposeIds ...int) bool {
-	if !c.IsVendorAllowed(id) {
-		return false
+	return c.vendorAllowedForPurposes(nil, nil, id, purposeIds, LegalBasisConsent)
+}
+
+// Returns true if transparency for vendor id's legitimate interest is established for all purpose ids
+// and publisher hasn't set restrictions for them
+func (c *CoreString) IsVendorAllowedForPurposesLI(id int, purposeIds ...int) bool {
+	return c.vendorAllowedForPurposes(nil, nil, id, purposeIds, LegalBasisLegitimateInterest)
+}
+
+// Returns true if user has given consent to vendor id processing all purposes ids
+// or if transparency for its legitimate interest is established in accordance with publisher restrictions
+func (c *CoreString) IsVendorAllowedForFlexiblePurposes(id int, purposeIds ...int) bool {
+	return c.vendorAllowedForFlexiblePurposes(nil, nil, id, purposeIds, LegalBasisConsent)
+}
+
+// Returns true if transparency for vendor id's legitimate interest is established for all purpose ids
+// or if user has given consent in accordance with publisher restrictions
+func (c *CoreString) IsVendorAllowedForFlexiblePurposesLI(id int, purposeIds ...int) bool {
+	return c.vendorAllowedForFlexiblePurposes(nil, nil, id, purposeIds, LegalBasisLegitimateInterest)
+}
+
+// Returns the Decision of IsSpecialFeatureAllowed
+func (c *CoreString) ExplainSpecialFeatureAllowed(id int) *Decision {
+	d := &Decision{}
+	if d.check(fieldSpecialFeatureOptIns, id, c.IsSpecialFeatureAllowed(id)) {
+		d.allow(LegalBasisConsent)
 	}
+	return d
+}
 
-	for _, p := range purposeIds {
-		if !c.IsPurposeAllowed(p) {
-			return false
-		}
+// Returns the Decision of IsPurposeAllowed
+func (c *CoreString) ExplainPurposeAllowed(id int) *Decision {
+	d := &Decision{}
+	if d.check(fieldPurposesConsent, id, c.IsPurposeAllowed(id)) {
+		d.allow(LegalBasisConsent)
 	}
+	return d
+}
 
-	for _, p := range purposeIds {
-		pr := c.GetPubRestrictionsForPurpose(p)
-		for _, r := range pr {
-			if (r.RestrictionType == RestrictionTypeNotAllowed || r.RestrictionType == RestrictionTypeRequireLI) && r.IsVendorIncluded(id) {
-				return false
-			}
-		}
+// Returns the Decision of IsPurposeLIAllowed
+func (c *CoreString) ExplainPurposeLIAllowed(id int) *Decision {
+	d := &Decision{}
+	if d.check(fieldPurposesLITransparency, id, c.IsPurposeLIAllowed(id)) {
+		d.allow(LegalBasisLegitimateInterest)
 	}
+	return d
+}
 
-	return true
+// Returns the Decision of IsVendorAllowed
+func (c *CoreString) ExplainVendorAllowed(id int) *Decision {
+	d := &Decision{}
+	if d.check(fieldVendorsConsent, id, c.IsVendorAllowed(id)) {
+		d.allow(LegalBasisConsent)
+	}
+	return d
 }
 
-// Returns true if transparency for vendor id's legitimate interest is established for all purpose ids
-// and publisher hasn't set restrictions for them
-func (c *CoreString) IsVendorAllowedForPurposesLI(id int, purposeIds ...int) bool {
-	if !c.IsVendorLIAllowed(id) {
-		return false
+// Returns the Decision of IsVendorLIAllowed
+func (c *CoreString) ExplainVendorLIAllowed(id int) *Decision {
+	d := &Decision{}
+	if d.check(fieldVendorsLITransparency, id, c.IsVendorLIAllowed(id)) {
+		d.allow(LegalBasisLegitimateInterest)
+	}
+	return d
+}
+
+// Returns the Decision of IsVendorAllowedForPurposes
+func (c *CoreString) ExplainVendorAllowedForPurposes(id int, purposeIds ...int) *Decision {
+	d := &Decision{}
+	c.vendorAllowedForPurposes(d, nil, id, purposeIds, LegalBasisConsent)
+	return d
+}
+
+// Returns the Decision of IsVendorAllowedForPurposesLI
+func (c *CoreString) ExplainVendorAllowedForPurposesLI(id int, purposeIds ...int) *Decision {
+	d := &Decision{}
+	c.vendorAllowedForPurposes(d, nil, id, purposeIds, LegalBasisLegitimateInterest)
+	return d
+}
+
+// Returns the Decision of IsVendorAllowedForFlexiblePurposes
+func (c *CoreString) ExplainVendorAllowedForFlexiblePurposes(id int, purposeIds ...int) *Decision {
+	d := &Decision{}
+	c.vendorAllowedForFlexiblePurposes(d, nil, id, purposeIds, LegalBasisConsent)
+	return d
+}
+
+// Returns the Decision of IsVendorAllowedForFlexiblePurposesLI
+func (c *CoreString) ExplainVendorAllowedForFlexiblePurposesLI(id int, purposeIds ...int) *Decision {
+	d := &Decision{}
+	c.vendorAllowedForFlexiblePurposes(d, nil, id, purposeIds, LegalBasisLegitimateInterest)
+	return d
+}
+
+// Checks that vendor id can process all purpose ids under legalBasis, with rules or those of TcfPolicyVersion when nil
+// Any publisher restriction applying to the vendor for a purpose other than legalBasis disallows it
+func (c *CoreString) vendorAllowedForPurposes(d *Decision, rules *PolicyRules, id int, purposeIds []int, legalBasis LegalBasis) bool {
+	if !c.checkVendor(d, id, legalBasis) {
+		return d.deny()
 	}
 
 	for _, p := range purposeIds {
-		if !c.IsPurposeLIAllowed(p) {
-			return false
+		if !c.checkPurpose(d, rules, p, legalBasis) {
+			return d.deny()
 		}
 	}
 
 	for _, p := range purposeIds {
-		pr := c.GetPubRestrictionsForPurpose(p)
-		for _, r := range pr {
-			if (r.RestrictionType == RestrictionTypeNotAllowed || r.RestrictionType == RestrictionTypeRequireConsent) && r.IsVendorIncluded(id) {
-				return false
+		for _, r := range c.PubRestrictions {
+			if r.PurposeId != p || !r.IsVendorIncluded(id) {
+				continue
+			}
+			if !d.checkRestriction(r, id, r.allows(legalBasis)) {
+				return d.deny()
 			}
 		}
+		d.use(p, legalBasis)
 	}
 
-	return true
+	return d.allow(legalBasis)
 }
 
-// Returns true if user has given consent to vendor id processing all purposes ids
-// or if transparency for its legitimate interest is established in accordance with publisher restrictions
-func (c *CoreString) IsVendorAllowedForFlexiblePurposes(id int, purposeIds ...int) bool {
-	if !c.IsVendorAllowed(id) && !c.IsVendorLIAllowed(id) {
-		return false
-	}
-
+// Checks that vendor id can process all purpose ids under legalBasis, with rules or those of TcfPolicyVersion when nil,
+// or under the legal basis required by the publisher restrictions applying to the vendor
+// When a purpose has publisher restrictions but none applies to the vendor, either signal of the vendor and the purpose is enough
+func (c *CoreString) vendorAllowedForFlexiblePurposes(d *Decision, rules *PolicyRules, id int, purposeIds []int, legalBasis LegalBasis) bool {
 	for _, p := range purposeIds {
-		if !c.IsPurposeAllowed(p) && !c.IsPurposeLIAllowed(p) {
-			return false
-		}
-
-		pr := c.GetPubRestrictionsForPurpose(p)
-		if len(pr) == 0 && (!c.IsVendorAllowed(id) || !c.IsPurposeAllowed(p)) {
-			return false
-		}
-
-		for _, r := range pr {
+		restricted := false
+		purposeLegalBasis := LegalBasisNone
+		for _, r := range c.PubRestrictions {
+			if r.PurposeId != p {
+				continue
+			}
+			restricted = true
 			if !r.IsVendorIncluded(id) {
 				continue
 			}
+			if !d.checkRestriction(r, id, r.RestrictionType != RestrictionTypeNotAllowed) {
+				return d.deny()
+			}
+
+			var required LegalBasis
 			switch r.RestrictionType {
-			case RestrictionTypeNotAllowed:
-				return false
 			case RestrictionTypeRequireConsent:
-				if !c.IsVendorAllowed(id) || !c.IsPurposeAllowed(p) {
-					return false
-				}
+				required = LegalBasisConsent
 			case RestrictionTypeRequireLI:
-				if !c.IsVendorLIAllowed(id) || !c.IsPurposeLIAllowed(p) {
-					return false
-				}
+				required = LegalBasisLegitimateInterest
+			default:
+				continue
 			}
+			if !c.checkVendor(d, id, required) || !c.checkPurpose(d, rules, p, required) {
+				return d.deny()
+			}
+			purposeLegalBasis = required
 		}
-	}
-
-	return true
-}
 
-// Returns true if transparency for vendor id's legitimate interest is established for all purpose ids
-// or if user has given consent in accordance with publisher restrictions
-func (c *CoreString) IsVendorAllowedForFlexiblePurposesLI(id int, purposeIds ...int) bool {
-	if !c.IsVendorAllowed(id) && !c.IsVendorLIAllowed(id) {
-		return false
+		switch {
+		case purposeLegalBasis != LegalBasisNone:
+		case !restricted:
+			if !c.checkVendor(d, id, legalBasis) || !c.checkPurpose(d, rules, p, legalBasis) {
+				return d.deny()
+			}
+			purposeLegalBasis = legalBasis
+		default:
+			vendorLegalBasis := checkEither(d, legalBasis, func(d *Decision, b LegalBasis) bool { return c.checkVendor(d, id, b) })
+			purposeLegalBasis = checkEither(d, legalBasis, func(d *Decision, b LegalBasis) bool { return c.checkPurpose(d, rules, p, b) })
+			if vendorLegalBasis == LegalBasisNone || purposeLegalBasis == LegalBasisNone {
+				return d.deny()
+			}
+		}
+		d.use(p, purposeLegalBasis)
 	}
 
-	for _, p := range purposeIds {
-		if !c.IsPurposeAllowed(p) && !c.IsPurposeLIAllowed(p) {
-			return false
+	// Without purposes, either signal of the vendor is enough
+	if len(purposeIds) == 0 {
+		legalBasis = checkEither(d, legalBasis, func(d *Decision, b LegalBasis) bool { return c.checkVendor(d, id, b) })
+		if legalBasis == LegalBasisNone {
+			return d.deny()
 		}
+	}
+
+	return d.allow(legalBasis)
+}
 
-		pr := c.GetPubRestrictionsForPurpose(p)
-		if len(pr) == 0 && (!c.IsVendorLIAllowed(id) || !c.IsPurposeLIAllowed(p)) {
-			return false
+// Returns legalBasis if check passes under it, else the other legal basis if check passes under it, else LegalBasisNone
+// Only the check of the returned legal basis, or of the other one if both fail, is recorded in d
+func checkEither(d *Decision, legalBasis LegalBasis, check func(d *Decision, legalBasis LegalBasis) bool) LegalBasis {
+	if !check(nil, legalBasis) {
+		if legalBasis == LegalBasisConsent {
+			legalBasis = LegalBasisLegitimateInterest
+		} else {
+			legalBasis = LegalBasisConsent
 		}
+	}
+	if !check(d, legalBasis) {
+		return LegalBasisNone
+	}
+	return legalBasis
+}
 
-		for _, r := range pr {
-			if !r.IsVendorIncluded(id) {
-				continue
-			}
-			switch r.RestrictionType {
-			case RestrictionTypeNotAllowed:
-				return false
-			case RestrictionTypeRequireConsent:
-				if !c.IsVendorAllowed(id) || !c.IsPurposeAllowed(p) {
-					return false
-				}
-			case RestrictionTypeRequireLI:
-				if !c.IsVendorLIAllowed(id) || !c.IsPurposeLIAllowed(p) {
-					return false
-				}
-			}
+func (c *CoreString) checkVendor(d *Decision, id int, legalBasis LegalBasis) bool {
+	if legalBasis == LegalBasisLegitimateInterest {
+		return d.check(fieldVendorsLITransparency, id, c.IsVendorLIAllowed(id))
+	}
+	return d.check(fieldVendorsConsent, id, c.IsVendorAllowed(id))
+}
+
+func (c *CoreString) checkPurpose(d *Decision, rules *PolicyRules, id int, legalBasis LegalBasis) bool {
+	if legalBasis == LegalBasisLegitimateInterest {
+		if rules == nil {
+			rules = policyRulesForVersion(c.TcfPolicyVersion)
 		}
+		return d.check(fieldPolicyNoLegitimateInterestPurposes, id, rules.AllowsLegitimateInterest(id)) &&
+			d.check(fieldPurposesLITransparency, id, c.IsPurposeLIAllowed(id))
 	}
+	return d.check(fieldPurposesConsent, id, c.IsPurposeAllowed(id))
+}
 
-	return true
+// Returns a copy of the policy rules applied by the legal basis checks, the rules of TcfPolicyVersion
+// Use WithPolicyRules to apply other rules
+func (c *CoreString) GetPolicyRules() *PolicyRules {
+	return PolicyRulesForVersion(c.TcfPolicyVersion)
 }
 
 // Returns a list of publisher restrictions applied to purpose id
@@ -262,6 +364,51 @@ func (p *PubRestriction) IsVendorIncluded(id int) bool {
 	return false
 }
 
+// Returns true if restriction doesn't prevent processing under legalBasis
+func (p *PubRestriction) allows(legalBasis LegalBasis) bool {
+	switch p.RestrictionType {
+	case RestrictionTypeNotAllowed:
+		return false
+	case RestrictionTypeRequireConsent:
+		return legalBasis == LegalBasisConsent
+	case RestrictionTypeRequireLI:
+		return legalBasis == LegalBasisLegitimateInterest
+	}
+	return true
+}
+
+// Sets the vendors user has given consent to with the shortest encoding
+// MaxVendorId, IsRangeEncoding, VendorsConsent, NumEntries, RangeEntries and VendorsConsentSet are derived from ids
+func (c *CoreString) SetVendorsConsent(ids ...int) {
+	set := NewIDSet(ids...)
+	e := newVendorEncoding(set)
+	c.MaxVendorId = e.maxVendorId
+	c.IsRangeEncoding = e.isRangeEncoding
+	c.VendorsConsent = e.bitField
+	c.NumEntries = len(e.rangeEntries)
+	c.RangeEntries = e.rangeEntries
+	c.VendorsConsentSet = set
+}
+
+// Sets the vendors with established legitimate interest transparency with the shortest encoding
+// MaxVendorIdLI, IsRangeEncodingLI, VendorsLITransparency, NumEntriesLI, RangeEntriesLI and VendorsLITransparencySet are derived from ids
+func (c *CoreString) SetVendorsLITransparency(ids ...int) {
+	set := NewIDSet(ids...)
+	e := newVendorEncoding(set)
+	c.MaxVendorIdLI = e.maxVendorId
+	c.IsRangeEncodingLI = e.isRangeEncoding
+	c.VendorsLITransparency = e.bitField
+	c.NumEntriesLI = len(e.rangeEntries)
+	c.RangeEntriesLI = e.rangeEntries
+	c.VendorsLITransparencySet = set
+}
+
+// Re-encodes vendors consent and legitimate interest transparency with the shortest encoding
+func (c *CoreString) OptimizeVendorEncoding() {
+	c.SetVendorsConsent(newIDSetFromEncoding(c.IsRangeEncoding, c.VendorsConsent, c.RangeEntries).IDs()...)
+	c.SetVendorsLITransparency(newIDSetFromEncoding(c.IsRangeEncodingLI, c.VendorsLITransparency, c.RangeEntriesLI).IDs()...)
+}
+
 // Returns structure as a base64 raw url encoded string
 func (c *CoreString) Encode() string {
 	var bitSize int
@@ -5,12 +5,13 @@ import (
 )
 
 type DisclosedVendors struct {
-	SegmentType      int
-	MaxVendorId      int
-	IsRangeEncoding  bool
-	DisclosedVendors map[int]bool
-	NumEntries       int
-	RangeEntries     []*RangeEntry
+	SegmentType         int
+	MaxVendorId         int
+	IsRangeEncoding     bool
+	DisclosedVendors    map[int]bool
+	DisclosedVendorsSet *IDSet
+	NumEntries          int
+	RangeEntries        []*RangeEntry
 }
 
 // Returns true if vendor id is disclosed for validating OOB signaling
@@ -27,6 +28,24 @@ func (d *DisclosedVendors) IsVendorDisclosed(id int) bool {
 	return d.DisclosedVendors[id]
 }
 
+// Sets the disclosed vendors with the shortest encoding
+// MaxVendorId, IsRangeEncoding, DisclosedVendors, NumEntries, RangeEntries and DisclosedVendorsSet are derived from ids
+func (d *DisclosedVendors) SetDisclosedVendors(ids ...int) {
+	set := NewIDSet(ids...)
+	e := newVendorEncoding(set)
+	d.MaxVendorId = e.maxVendorId
+	d.IsRangeEncoding = e.isRangeEncoding
+	d.DisclosedVendors = e.bitField
+	d.NumEntries = len(e.rangeEntries)
+	d.RangeEntries = e.rangeEntries
+	d.DisclosedVendorsSet = set
+}
+
+// Re-encodes disclosed vendors with the shortest encoding
+func (d *DisclosedVendors) OptimizeVendorEncoding() {
+	d.SetDisclosedVendors(newIDSetFromEncoding(d.IsRangeEncoding, d.DisclosedVendors, d.RangeEntries).IDs()...)
+}
+
 // Returns structure as a base64 raw url encoded string
 func (d *DisclosedVendors) Encode() string {
 	var bitSize int
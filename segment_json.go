@@ -0,0 +1,435 @@
+package iabtcfv2
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Version of the JSON schema of the segments, written as "schemaVersion"
+// Unmarshaling a segment with a higher schema version returns ErrUnsupportedVersion,
+// and a segment without schema version is read as the current one
+const JSONSchemaVersion = 1
+
+type coreStringJSON struct {
+	SchemaVersion          int               `json:"schemaVersion"`
+	Version                int               `json:"version"`
+	Created                time.Time         `json:"created"`
+	LastUpdated            time.Time         `json:"lastUpdated"`
+	CmpId                  int               `json:"cmpId"`
+	CmpVersion             int               `json:"cmpVersion"`
+	ConsentScreen          int               `json:"consentScreen"`
+	ConsentLanguage        string            `json:"consentLanguage"`
+	VendorListVersion      int               `json:"vendorListVersion"`
+	TcfPolicyVersion       int               `json:"tcfPolicyVersion"`
+	IsServiceSpecific      bool              `json:"isServiceSpecific"`
+	UseNonStandardTexts    bool              `json:"useNonStandardTexts"`
+	SpecialFeatureOptIns   []int             `json:"specialFeatureOptIns"`
+	PurposesConsent        []int             `json:"purposesConsent"`
+	PurposesLITransparency []int             `json:"purposesLITransparency"`
+	PurposeOneTreatment    bool              `json:"purposeOneTreatment"`
+	PublisherCC            string            `json:"publisherCC"`
+	MaxVendorId            int               `json:"maxVendorId"`
+	IsRangeEncoding        bool              `json:"isRangeEncoding"`
+	VendorsConsent         []int             `json:"vendorsConsent"`
+	RangeEntries           []*RangeEntry     `json:"rangeEntries,omitempty"`
+	MaxVendorIdLI          int               `json:"maxVendorIdLI"`
+	IsRangeEncodingLI      bool              `json:"isRangeEncodingLI"`
+	VendorsLITransparency  []int             `json:"vendorsLITransparency"`
+	RangeEntriesLI         []*RangeEntry     `json:"rangeEntriesLI,omitempty"`
+	PubRestrictions        []*PubRestriction `json:"pubRestrictions"`
+}
+
+type vendorsSegmentJSON struct {
+	SchemaVersion    int           `json:"schemaVersion"`
+	SegmentType      int           `json:"segmentType"`
+	MaxVendorId      int           `json:"maxVendorId"`
+	IsRangeEncoding  bool          `json:"isRangeEncoding"`
+	DisclosedVendors []int         `json:"disclosedVendors,omitempty"`
+	AllowedVendors   []int         `json:"allowedVendors,omitempty"`
+	RangeEntries     []*RangeEntry `json:"rangeEntries,omitempty"`
+}
+
+type publisherTCJSON struct {
+	SchemaVersion                int   `json:"schemaVersion"`
+	SegmentType                  int   `json:"segmentType"`
+	PubPurposesConsent           []int `json:"pubPurposesConsent"`
+	PubPurposesLITransparency    []int `json:"pubPurposesLITransparency"`
+	NumCustomPurposes            int   `json:"numCustomPurposes"`
+	CustomPurposesConsent        []int `json:"customPurposesConsent"`
+	CustomPurposesLITransparency []int `json:"customPurposesLITransparency"`
+}
+
+type pubRestrictionJSON struct {
+	PurposeId       int           `json:"purposeId"`
+	RestrictionType int           `json:"restrictionType"`
+	RangeEntries    []*RangeEntry `json:"rangeEntries"`
+}
+
+type rangeEntryJSON struct {
+	StartVendorID int `json:"startVendorId"`
+	EndVendorID   int `json:"endVendorId"`
+}
+
+type tcDataJSON struct {
+	CoreString       *CoreString       `json:"coreString,omitempty"`
+	DisclosedVendors *DisclosedVendors `json:"disclosedVendors,omitempty"`
+	AllowedVendors   *AllowedVendors   `json:"allowedVendors,omitempty"`
+	PublisherTC      *PublisherTC      `json:"publisherTC,omitempty"`
+}
+
+// Marshals the segments with their JSON schema
+func (t *TCData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&tcDataJSON{
+		CoreString:       t.CoreString,
+		DisclosedVendors: t.DisclosedVendors,
+		AllowedVendors:   t.AllowedVendors,
+		PublisherTC:      t.PublisherTC,
+	})
+}
+
+// Unmarshals the segments from their JSON schema
+func (t *TCData) UnmarshalJSON(b []byte) error {
+	var j tcDataJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+
+	*t = TCData(j)
+	return nil
+}
+
+// Marshals the Core String with lower camel case field names, sorted arrays of ids and RFC 3339 timestamps
+// Vendors are always listed as ids, and their range entries are added when range encoding is used,
+// so that the Core String can be encoded again exactly as it was decoded
+func (c *CoreString) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&coreStringJSON{
+		SchemaVersion:          JSONSchemaVersion,
+		Version:                c.Version,
+		Created:                c.Created.UTC(),
+		LastUpdated:            c.LastUpdated.UTC(),
+		CmpId:                  c.CmpId,
+		CmpVersion:             c.CmpVersion,
+		ConsentScreen:          c.ConsentScreen,
+		ConsentLanguage:        c.ConsentLanguage,
+		VendorListVersion:      c.VendorListVersion,
+		TcfPolicyVersion:       c.TcfPolicyVersion,
+		IsServiceSpecific:      c.IsServiceSpecific,
+		UseNonStandardTexts:    c.UseNonStandardTexts,
+		SpecialFeatureOptIns:   bitFieldIDs(c.SpecialFeatureOptIns),
+		PurposesConsent:        bitFieldIDs(c.PurposesConsent),
+		PurposesLITransparency: bitFieldIDs(c.PurposesLITransparency),
+		PurposeOneTreatment:    c.PurposeOneTreatment,
+		PublisherCC:            c.PublisherCC,
+		MaxVendorId:            c.MaxVendorId,
+		IsRangeEncoding:        c.IsRangeEncoding,
+		VendorsConsent:         newIDSetFromEncoding(c.IsRangeEncoding, c.VendorsConsent, c.RangeEntries).IDs(),
+		RangeEntries:           rangeEntriesJSON(c.IsRangeEncoding, c.RangeEntries),
+		MaxVendorIdLI:          c.MaxVendorIdLI,
+		IsRangeEncodingLI:      c.IsRangeEncodingLI,
+		VendorsLITransparency:  newIDSetFromEncoding(c.IsRangeEncodingLI, c.VendorsLITransparency, c.RangeEntriesLI).IDs(),
+		RangeEntriesLI:         rangeEntriesJSON(c.IsRangeEncodingLI, c.RangeEntriesLI),
+		PubRestrictions:        pubRestrictionsJSON(c.PubRestrictions),
+	})
+}
+
+// Unmarshals the Core String from its JSON schema
+// Entry counts and vendor sets are derived, and when range encoding is used without range entries,
+// they are derived from the vendor ids
+func (c *CoreString) UnmarshalJSON(b []byte) error {
+	var j coreStringJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	if err := checkJSONSchemaVersion(SegmentTypeCoreString, j.SchemaVersion); err != nil {
+		return err
+	}
+
+	vendors, err := newVendorsFromJSON(SegmentTypeCoreString, "VendorsConsent", j.MaxVendorId, j.IsRangeEncoding, j.VendorsConsent, j.RangeEntries)
+	if err != nil {
+		return err
+	}
+	vendorsLI, err := newVendorsFromJSON(SegmentTypeCoreString, "VendorsLITransparency", j.MaxVendorIdLI, j.IsRangeEncodingLI, j.VendorsLITransparency, j.RangeEntriesLI)
+	if err != nil {
+		return err
+	}
+
+	*c = CoreString{
+		Version:                  j.Version,
+		Created:                  j.Created.UTC(),
+		LastUpdated:              j.LastUpdated.UTC(),
+		CmpId:                    j.CmpId,
+		CmpVersion:               j.CmpVersion,
+		ConsentScreen:            j.ConsentScreen,
+		ConsentLanguage:          j.ConsentLanguage,
+		VendorListVersion:        j.VendorListVersion,
+		TcfPolicyVersion:         j.TcfPolicyVersion,
+		IsServiceSpecific:        j.IsServiceSpecific,
+		UseNonStandardTexts:      j.UseNonStandardTexts,
+		SpecialFeatureOptIns:     idsBitField(j.SpecialFeatureOptIns),
+		PurposesConsent:          idsBitField(j.PurposesConsent),
+		PurposesLITransparency:   idsBitField(j.PurposesLITransparency),
+		PurposeOneTreatment:      j.PurposeOneTreatment,
+		PublisherCC:              j.PublisherCC,
+		MaxVendorId:              vendors.maxVendorId,
+		IsRangeEncoding:          vendors.isRangeEncoding,
+		VendorsConsent:           vendors.bitField,
+		VendorsConsentSet:        vendors.set,
+		NumEntries:               len(vendors.rangeEntries),
+		RangeEntries:             vendors.rangeEntries,
+		MaxVendorIdLI:            vendorsLI.maxVendorId,
+		IsRangeEncodingLI:        vendorsLI.isRangeEncoding,
+		VendorsLITransparency:    vendorsLI.bitField,
+		VendorsLITransparencySet: vendorsLI.set,
+		NumEntriesLI:             len(vendorsLI.rangeEntries),
+		RangeEntriesLI:           vendorsLI.rangeEntries,
+		NumPubRestrictions:       len(j.PubRestrictions),
+		PubRestrictions:          j.PubRestrictions,
+	}
+	return nil
+}
+
+// Marshals the Disclosed Vendors segment like the vendors of CoreString.MarshalJSON
+func (d *DisclosedVendors) MarshalJSON() ([]byte, error) {
+	ids := newIDSetFromEncoding(d.IsRangeEncoding, d.DisclosedVendors, d.RangeEntries).IDs()
+	return json.Marshal(&vendorsSegmentJSON{
+		SchemaVersion:    JSONSchemaVersion,
+		SegmentType:      d.SegmentType,
+		MaxVendorId:      d.MaxVendorId,
+		IsRangeEncoding:  d.IsRangeEncoding,
+		DisclosedVendors: ids,
+		RangeEntries:     rangeEntriesJSON(d.IsRangeEncoding, d.RangeEntries),
+	})
+}
+
+// Unmarshals the Disclosed Vendors segment from its JSON schema
+func (d *DisclosedVendors) UnmarshalJSON(b []byte) error {
+	var j vendorsSegmentJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	if err := checkJSONSchemaVersion(SegmentTypeDisclosedVendors, j.SchemaVersion); err != nil {
+		return err
+	}
+
+	vendors, err := newVendorsFromJSON(SegmentTypeDisclosedVendors, "DisclosedVendors", j.MaxVendorId, j.IsRangeEncoding, j.DisclosedVendors, j.RangeEntries)
+	if err != nil {
+		return err
+	}
+
+	*d = DisclosedVendors{
+		SegmentType:         j.SegmentType,
+		MaxVendorId:         vendors.maxVendorId,
+		IsRangeEncoding:     vendors.isRangeEncoding,
+		DisclosedVendors:    vendors.bitField,
+		DisclosedVendorsSet: vendors.set,
+		NumEntries:          len(vendors.rangeEntries),
+		RangeEntries:        vendors.rangeEntries,
+	}
+	return nil
+}
+
+// Marshals the Allowed Vendors segment like the vendors of CoreString.MarshalJSON
+func (a *AllowedVendors) MarshalJSON() ([]byte, error) {
+	ids := newIDSetFromEncoding(a.IsRangeEncoding, a.AllowedVendors, a.RangeEntries).IDs()
+	return json.Marshal(&vendorsSegmentJSON{
+		SchemaVersion:   JSONSchemaVersion,
+		SegmentType:     a.SegmentType,
+		MaxVendorId:     a.MaxVendorId,
+		IsRangeEncoding: a.IsRangeEncoding,
+		AllowedVendors:  ids,
+		RangeEntries:    rangeEntriesJSON(a.IsRangeEncoding, a.RangeEntries),
+	})
+}
+
+// Unmarshals the Allowed Vendors segment from its JSON schema
+func (a *AllowedVendors) UnmarshalJSON(b []byte) error {
+	var j vendorsSegmentJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	if err := checkJSONSchemaVersion(SegmentTypeAllowedVendors, j.SchemaVersion); err != nil {
+		return err
+	}
+
+	vendors, err := newVendorsFromJSON(SegmentTypeAllowedVendors, "AllowedVendors", j.MaxVendorId, j.IsRangeEncoding, j.AllowedVendors, j.RangeEntries)
+	if err != nil {
+		return err
+	}
+
+	*a = AllowedVendors{
+		SegmentType:       j.SegmentType,
+		MaxVendorId:       vendors.maxVendorId,
+		IsRangeEncoding:   vendors.isRangeEncoding,
+		AllowedVendors:    vendors.bitField,
+		AllowedVendorsSet: vendors.set,
+		NumEntries:        len(vendors.rangeEntries),
+		RangeEntries:      vendors.rangeEntries,
+	}
+	return nil
+}
+
+// Marshals the Publisher TC segment with lower camel case field names and sorted arrays of purpose ids
+func (p *PublisherTC) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&publisherTCJSON{
+		SchemaVersion:                JSONSchemaVersion,
+		SegmentType:                  p.SegmentType,
+		PubPurposesConsent:           bitFieldIDs(p.PubPurposesConsent),
+		PubPurposesLITransparency:    bitFieldIDs(p.PubPurposesLITransparency),
+		NumCustomPurposes:            p.NumCustomPurposes,
+		CustomPurposesConsent:        bitFieldIDs(p.CustomPurposesConsent),
+		CustomPurposesLITransparency: bitFieldIDs(p.CustomPurposesLITransparency),
+	})
+}
+
+// Unmarshals the Publisher TC segment from its JSON schema
+func (p *PublisherTC) UnmarshalJSON(b []byte) error {
+	var j publisherTCJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	if err := checkJSONSchemaVersion(SegmentTypePublisherTC, j.SchemaVersion); err != nil {
+		return err
+	}
+
+	*p = PublisherTC{
+		SegmentType:                  j.SegmentType,
+		PubPurposesConsent:           idsBitField(j.PubPurposesConsent),
+		PubPurposesLITransparency:    idsBitField(j.PubPurposesLITransparency),
+		NumCustomPurposes:            j.NumCustomPurposes,
+		CustomPurposesConsent:        idsBitField(j.CustomPurposesConsent),
+		CustomPurposesLITransparency: idsBitField(j.CustomPurposesLITransparency),
+	}
+	return nil
+}
+
+// Marshals the publisher restriction with its restriction type as a number
+func (r *PubRestriction) MarshalJSON() ([]byte, error) {
+	entries := r.RangeEntries
+	if entries == nil {
+		entries = []*RangeEntry{}
+	}
+	return json.Marshal(&pubRestrictionJSON{
+		PurposeId:       r.PurposeId,
+		RestrictionType: int(r.RestrictionType),
+		RangeEntries:    entries,
+	})
+}
+
+// Unmarshals the publisher restriction, deriving NumEntries and VendorSet from its range entries
+func (r *PubRestriction) UnmarshalJSON(b []byte) error {
+	var j pubRestrictionJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+
+	*r = PubRestriction{
+		PurposeId:       j.PurposeId,
+		RestrictionType: RestrictionType(j.RestrictionType),
+		NumEntries:      len(j.RangeEntries),
+		RangeEntries:    j.RangeEntries,
+		VendorSet:       newIDSetFromEncoding(true, nil, j.RangeEntries),
+	}
+	return nil
+}
+
+func (r *RangeEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rangeEntryJSON(*r))
+}
+
+func (r *RangeEntry) UnmarshalJSON(b []byte) error {
+	var j rangeEntryJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+
+	*r = RangeEntry(j)
+	return nil
+}
+
+func checkJSONSchemaVersion(segmentType SegmentType, version int) error {
+	if version > JSONSchemaVersion {
+		return &DecodeError{SegmentType: segmentType, Field: "SchemaVersion", Err: fmt.Errorf("%w: JSON schema %d", ErrUnsupportedVersion, version)}
+	}
+	return nil
+}
+
+// Returns the ids set in a bit field in ascending order
+func bitFieldIDs(bitField map[int]bool) []int {
+	ids := make([]int, 0, len(bitField))
+	for id, v := range bitField {
+		if v {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func idsBitField(ids []int) map[int]bool {
+	m := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		m[id] = true
+	}
+	return m
+}
+
+// Returns the range entries to marshal, only when range encoding is used
+func rangeEntriesJSON(isRangeEncoding bool, entries []*RangeEntry) []*RangeEntry {
+	if !isRangeEncoding {
+		return nil
+	}
+	if entries == nil {
+		return []*RangeEntry{}
+	}
+	return entries
+}
+
+func pubRestrictionsJSON(restrictions []*PubRestriction) []*PubRestriction {
+	if restrictions == nil {
+		return []*PubRestriction{}
+	}
+	return restrictions
+}
+
+type vendorsFromJSON struct {
+	maxVendorId     int
+	isRangeEncoding bool
+	bitField        map[int]bool
+	rangeEntries    []*RangeEntry
+	set             *IDSet
+}
+
+// Returns the vendor fields of a segment from the vendor ids and range entries of its JSON
+// Range entries are derived from the vendor ids when missing, and must hold the same ids otherwise
+// No id can be above maxVendorId, which defaults to the highest id when 0
+func newVendorsFromJSON(segmentType SegmentType, field string, maxVendorId int, isRangeEncoding bool, ids []int, entries []*RangeEntry) (*vendorsFromJSON, error) {
+	v := &vendorsFromJSON{maxVendorId: maxVendorId, isRangeEncoding: isRangeEncoding}
+	set := NewIDSet(ids...)
+
+	if isRangeEncoding {
+		if entries == nil {
+			entries = set.Ranges()
+		}
+		v.rangeEntries = entries
+		v.set = newIDSetFromEncoding(true, nil, entries)
+		if ids != nil && (set.difference(v.set).Len() > 0 || v.set.difference(set).Len() > 0) {
+			return nil, &DecodeError{SegmentType: segmentType, Field: field, Err: fmt.Errorf("%w: vendor ids don't match range entries", ErrInvalidValue)}
+		}
+		set = v.set
+	}
+
+	if v.maxVendorId == 0 {
+		v.maxVendorId = set.Max()
+	}
+	if set.Max() > v.maxVendorId {
+		return nil, &DecodeError{SegmentType: segmentType, Field: field, Err: fmt.Errorf("%w: vendor %d above %d", ErrVendorIdOutOfRange, set.Max(), v.maxVendorId)}
+	}
+	if isRangeEncoding {
+		return v, nil
+	}
+	v.bitField = idsBitField(set.IDs())
+	v.set = set
+	return v, nil
+}
@@ -0,0 +1,98 @@
+package iabtcfv2
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSegmentJSONRoundTrip(t *testing.T) {
+	strs := []string{
+		"CPTZZYAPTZZYABcABCENAgCMAPzAAEPAAAqIDaQBQAMgAgABqAR0A2gDaQAwAMgAgANoAAA.IDaQBQAMgAgABqAR0A2g.QDaQAgAMwAgADUA.eEAAAAAAAUA",
+		"CPStgrQPStgrQAGABCDEB9CsAP_AAH_AAAqIH-NN7S__a2Pj-359Q_t0eY1f9953v-UhjhaZk6QF0bPDsL8V4mM6vE3opioKuBYEO3LAIQRlHKHcBQGAaokRoTPsbk2MLpAAJ7PEmgMbEmdIGHV9m93DnZKYz3w-2r6T_u4NRP_M5MfpP41v3Wt5tl06qXTTVz8YhLP1cAABAAAAQPiAIEBAUAgAEMAEQAFCIQAAQpiQAAAABBCABAAAAIiAAQVwAZIIEAAARAAAQAABAQgwAAAAAABCAAAACwQCAACAQAAgAEAAAAEJAIBACAEAAAEAJABACACECAggAAAwDAgAACCABABAAACJDAAAMIIASABgBEAABEgAGAAACAoMgFgBMAEcAMsAfYBWwExAJsAWwAz4BygD4hEAkAZYBTwDqgHyAQ6AkQBNgDPgHKCQAIDfxAAEAEgSBUAAgABYAFQAMgAcAA8ACAAGUANAA1AB5AEQARQAmABvADmAHoAP0AiACJAEsAJoAUoAtwBhwDKAMsAaoA-wB-gEUAKeAbQA3AB8gEOgJEATEAmwBTQC2AGSAM-AaQA1iByYHKBQAYAigBfAO3CAAwASAGiAU-GgGgBcAGWAQUAp8BaAFpAOqAfIBDoCRAE2AMYAZ8A5QOABAb-KgGABMAC4AI4AZcBaAFpASCAmIBNgCmwFsAM-AcoOgZAALAAqABkADgAIIAYgBlADQANQAeAA-gCIAIoATAAuABiADMAG8AOYAegA_ACIAEsAJgATQAowBSgC3AGGAMoAaIA-wB-gEUAKfAWgBaQC8gG4AOoAh0BIICRAE2AKagWwBbIDGAGSAMsAZmAz4BpADWIHJgcoPADAAqAEUAL4AjIDfwHbjgAIAJCEBYABYAGQAYgBMAC4AGIAMwAbwA9ACOAH2ARQAoYBT4C0ALSAdQBIICRAE2AKagWwBbIDPiIAMAFQAvgCMkoEAACAAFgAZAA4AB8AGIAPAAiABMAC4AGIAMwAbYBEAESAKMAUoAtwBqgEnAKfAWgBaQDcAHUAPkAh0BIgCbAFsAMsAZ8A0gBrBMAEARkBv5SBQAAsACoAGQAOAAggBiAGUANAA1AB5AEQARQAmABSADEAGYAOYAfgBEACjAFKALcAZQA0QBqgD7AKGAVsAvIBtADcAIdASIAk4BNgC2AGMAMkAZYAz4BpADWIHJgcoVACAAqAB8AL4Bv5QAGACQAk4BOw.YAAAAAAAAAAA",
+		"COxR03kOxR1CqBcABCENAgCMAP_AAH_AAAqIF3EXySoGY2thI2YVFxBEIYwfJxyigMgChgQIsSwNQIeFLBoGLiAAHBGYJAQAGBAEEACBAQIkHGBMCQAAgAgBiRCMQEGMCzNIBIBAggEbY0FACCVmHkHSmZCY7064O__QLuIJEFQMAkSBAIACLECIQwAQDiAAAYAlAAABAhIaAAgIWBQEeAAAACAwAAgAAABBAAACAAQAAICIAAABAAAgAiAQAAAAGgIQAACBABACRIAAAEANCAAgiCEAQg4EAo4AAA",
+	}
+
+	for _, str := range strs {
+		data, err := Decode(str)
+		if err != nil {
+			t.Errorf("TC String should be decoded without error: %s", err)
+			continue
+		}
+
+		b, err := json.Marshal(data)
+		if err != nil {
+			t.Errorf("TCData should be marshaled without error: %s", err)
+			continue
+		}
+
+		var u TCData
+		if err := json.Unmarshal(b, &u); err != nil {
+			t.Errorf("TCData should be unmarshaled without error: %s", err)
+			continue
+		}
+		if u.ToTCString() != data.ToTCString() {
+			t.Errorf("Unmarshaled TCData should be encoded as decoded: %s", str)
+		}
+
+		again, _ := json.Marshal(&u)
+		if string(again) != string(b) {
+			t.Errorf("Unmarshaled TCData should be marshaled as decoded:\n%s\n%s", b, again)
+		}
+		if u.CoreString.VendorsConsentSet.Len() != data.CoreString.VendorsConsentSet.Len() {
+			t.Errorf("Vendor sets should be derived")
+		}
+	}
+}
+
+func TestCoreStringJSON(t *testing.T) {
+	data, _ := Decode("CPTZZYAPTZZYABcABCENAgCMAPzAAEPAAAqIDaQBQAMgAgABqAR0A2gDaQAwAMgAgANoAAA")
+	b, _ := json.Marshal(data.CoreString)
+
+	var m map[string]interface{}
+	json.Unmarshal(b, &m)
+	if m["schemaVersion"] != float64(JSONSchemaVersion) || m["created"] != "2022-01-26T00:00:00Z" || m["cmpId"] != float64(92) {
+		t.Errorf("Unexpected JSON: %s", b)
+	}
+	if _, ok := m["VendorsConsentSet"]; ok {
+		t.Errorf("Derived fields should not be marshaled: %s", b)
+	}
+
+	// Range entries are derived from the vendor ids
+	var c CoreString
+	err := json.Unmarshal([]byte(`{"version":2,"created":"2022-01-26T00:00:00Z","lastUpdated":"2022-01-26T00:00:00Z","consentLanguage":"EN","publisherCC":"FR","isRangeEncoding":true,"vendorsConsent":[3,1,2,10],"pubRestrictions":[{"purposeId":2,"restrictionType":1,"rangeEntries":[{"startVendorId":1,"endVendorId":3}]}]}`), &c)
+	if err != nil || c.NumEntries != 2 || c.RangeEntries[0].EndVendorID != 3 || !c.IsVendorAllowed(10) {
+		t.Errorf("Range entries should be derived from vendor ids: %v %+v", err, c)
+	}
+	if c.NumPubRestrictions != 1 || c.PubRestrictions[0].RestrictionType != RestrictionTypeRequireConsent || !c.PubRestrictions[0].VendorSet.Contains(2) {
+		t.Errorf("Publisher restrictions should be unmarshaled: %+v", c.PubRestrictions)
+	}
+
+	if c.MaxVendorId != 10 {
+		t.Errorf("Max vendor id should default to the highest range entry end: %d", c.MaxVendorId)
+	}
+	if _, _, err := DecodeWithOptions(c.Encode(), DecodeOptions{Mode: DecodeModeStrict}); err != nil {
+		t.Errorf("Unmarshaled range entries should be encoded as a valid TC String: %s", err)
+	}
+
+	err = json.Unmarshal([]byte(`{"maxVendorId":5,"vendorsConsent":[6]}`), &c)
+	if !errors.Is(err, ErrVendorIdOutOfRange) {
+		t.Errorf("Vendor ids above max vendor id should return ErrVendorIdOutOfRange: %v", err)
+	}
+
+	err = json.Unmarshal([]byte(`{"maxVendorId":5,"isRangeEncoding":true,"rangeEntries":[{"startVendorId":3,"endVendorId":7}]}`), &c)
+	if !errors.Is(err, ErrVendorIdOutOfRange) {
+		t.Errorf("Range entries above max vendor id should return ErrVendorIdOutOfRange: %v", err)
+	}
+
+	err = json.Unmarshal([]byte(`{"isRangeEncoding":true,"vendorsConsent":[3,7],"rangeEntries":[{"startVendorId":3,"endVendorId":3}]}`), &c)
+	if !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Vendor ids not matching range entries should return ErrInvalidValue: %v", err)
+	}
+
+	err = json.Unmarshal([]byte(`{"schemaVersion":2}`), &c)
+	if !errors.Is(err, ErrUnsupportedVersion) || !strings.Contains(err.Error(), "JSON schema 2") {
+		t.Errorf("Unknown schema version should return ErrUnsupportedVersion: %v", err)
+	}
+}
@@ -33,6 +33,42 @@ func (p *PublisherTC) IsCustomPurposeLIAllowed(id int) bool {
 	return p.CustomPurposesLITransparency[id]
 }
 
+// Returns the Decision of IsPurposeAllowed
+func (p *PublisherTC) ExplainPurposeAllowed(id int) *Decision {
+	d := &Decision{}
+	if d.check(fieldPubPurposesConsent, id, p.IsPurposeAllowed(id)) {
+		d.allow(LegalBasisConsent)
+	}
+	return d
+}
+
+// Returns the Decision of IsPurposeLIAllowed
+func (p *PublisherTC) ExplainPurposeLIAllowed(id int) *Decision {
+	d := &Decision{}
+	if d.check(fieldPubPurposesLITransparency, id, p.IsPurposeLIAllowed(id)) {
+		d.allow(LegalBasisLegitimateInterest)
+	}
+	return d
+}
+
+// Returns the Decision of IsCustomPurposeAllowed
+func (p *PublisherTC) ExplainCustomPurposeAllowed(id int) *Decision {
+	d := &Decision{}
+	if d.check(fieldCustomPurposesConsent, id, p.IsCustomPurposeAllowed(id)) {
+		d.allow(LegalBasisConsent)
+	}
+	return d
+}
+
+// Returns the Decision of IsCustomPurposeLIAllowed
+func (p *PublisherTC) ExplainCustomPurposeLIAllowed(id int) *Decision {
+	d := &Decision{}
+	if d.check(fieldCustomPurposesLITransparency, id, p.IsCustomPurposeLIAllowed(id)) {
+		d.allow(LegalBasisLegitimateInterest)
+	}
+	return d
+}
+
 // Returns structure as a base64 raw url encoded string
 func (p *PublisherTC) Encode() string {
 	var bitSize int
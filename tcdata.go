@@ -5,6 +5,7 @@ import "strings"
 type TCData struct {
 	CoreString       *CoreString
 	DisclosedVendors *DisclosedVendors
+	AllowedVendors   *AllowedVendors
 	PublisherTC      *PublisherTC
 }
 
@@ -59,11 +60,78 @@ func (t *TCData) IsVendorAllowedForFlexiblePurposesLI(id int, purposeIds ...int)
 	return t.CoreString.IsVendorAllowedForFlexiblePurposesLI(id, purposeIds...)
 }
 
+// Returns true if the Allowed Vendors segment is present
+// and publisher allows vendor id to use OOB signaling
+func (t *TCData) IsVendorAllowedOOB(id int) bool {
+	if t.AllowedVendors == nil {
+		return false
+	}
+	return t.AllowedVendors.IsVendorAllowed(id)
+}
+
 // Returns a list of publisher restrictions applied to purpose id
 func (t *TCData) GetPubRestrictionsForPurpose(id int) []*PubRestriction {
 	return t.CoreString.GetPubRestrictionsForPurpose(id)
 }
 
+// Returns the Decision of IsSpecialFeatureAllowed
+func (t *TCData) ExplainSpecialFeatureAllowed(id int) *Decision {
+	return t.CoreString.ExplainSpecialFeatureAllowed(id)
+}
+
+// Returns the Decision of IsPurposeAllowed
+func (t *TCData) ExplainPurposeAllowed(id int) *Decision {
+	return t.CoreString.ExplainPurposeAllowed(id)
+}
+
+// Returns the Decision of IsPurposeLIAllowed
+func (t *TCData) ExplainPurposeLIAllowed(id int) *Decision {
+	return t.CoreString.ExplainPurposeLIAllowed(id)
+}
+
+// Returns the Decision of IsVendorAllowed
+func (t *TCData) ExplainVendorAllowed(id int) *Decision {
+	return t.CoreString.ExplainVendorAllowed(id)
+}
+
+// Returns the Decision of IsVendorLIAllowed
+func (t *TCData) ExplainVendorLIAllowed(id int) *Decision {
+	return t.CoreString.ExplainVendorLIAllowed(id)
+}
+
+// Returns the Decision of IsVendorAllowedForPurposes
+func (t *TCData) ExplainVendorAllowedForPurposes(id int, purposeIds ...int) *Decision {
+	return t.CoreString.ExplainVendorAllowedForPurposes(id, purposeIds...)
+}
+
+// Returns the Decision of IsVendorAllowedForPurposesLI
+func (t *TCData) ExplainVendorAllowedForPurposesLI(id int, purposeIds ...int) *Decision {
+	return t.CoreString.ExplainVendorAllowedForPurposesLI(id, purposeIds...)
+}
+
+// Returns the Decision of IsVendorAllowedForFlexiblePurposes
+func (t *TCData) ExplainVendorAllowedForFlexiblePurposes(id int, purposeIds ...int) *Decision {
+	return t.CoreString.ExplainVendorAllowedForFlexiblePurposes(id, purposeIds...)
+}
+
+// Returns the Decision of IsVendorAllowedForFlexiblePurposesLI
+func (t *TCData) ExplainVendorAllowedForFlexiblePurposesLI(id int, purposeIds ...int) *Decision {
+	return t.CoreString.ExplainVendorAllowedForFlexiblePurposesLI(id, purposeIds...)
+}
+
+// Re-encodes the vendors of all segments with the shortest encoding
+func (t *TCData) OptimizeVendorEncoding() {
+	if t.CoreString != nil {
+		t.CoreString.OptimizeVendorEncoding()
+	}
+	if t.DisclosedVendors != nil {
+		t.DisclosedVendors.OptimizeVendorEncoding()
+	}
+	if t.AllowedVendors != nil {
+		t.AllowedVendors.OptimizeVendorEncoding()
+	}
+}
+
 // Returns structure as a base64 raw url encoded string
 func (t *TCData) ToTCString() string {
 	var segments []string
@@ -74,6 +142,9 @@ func (t *TCData) ToTCString() string {
 	if t.DisclosedVendors != nil {
 		segments = append(segments, t.DisclosedVendors.Encode())
 	}
+	if t.AllowedVendors != nil {
+		segments = append(segments, t.AllowedVendors.Encode())
+	}
 	if t.PublisherTC != nil {
 		segments = append(segments, t.PublisherTC.Encode())
 	}
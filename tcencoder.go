@@ -119,3 +119,77 @@ func (r *TCEncoder) ReadPubRestrictions() (int, []*PubRestriction) {
 	}
 	return n, ret
 }
+
+// Longest Fibonacci code read, terminating bit included, so that values fit in an int64
+const MaxFibonacciIntBits = 64
+
+// Reads an integer coded with Fibonacci coding, as used by GPP Strings:
+// the Zeckendorf representation from the smallest Fibonacci number, terminated by two consecutive 1 bits
+// Returns 0 if the code isn't terminated within MaxFibonacciIntBits bits
+func (r *TCEncoder) ReadFibonacciInt() int {
+	v := 0
+	previous := false
+	for i, f1, f2 := 0, 1, 2; i < MaxFibonacciIntBits; i, f1, f2 = i+1, f2, f1+f2 {
+		bit := r.ReadBool()
+		if bit && previous {
+			return v
+		}
+		if bit {
+			v += f1
+		}
+		previous = bit
+	}
+	return 0
+}
+
+// Writes v >= 1 with Fibonacci coding
+func (r *TCEncoder) WriteFibonacciInt(v int) {
+	for _, bit := range fibonacciCode(v) {
+		r.WriteBool(bit)
+	}
+}
+
+// Returns the number of bits of v >= 1 coded with Fibonacci coding
+func FibonacciIntBitSize(v int) int {
+	return len(fibonacciCode(v))
+}
+
+func fibonacciCode(v int) []bool {
+	fibs := []int{1, 2}
+	for fibs[len(fibs)-1] <= v {
+		fibs = append(fibs, fibs[len(fibs)-1]+fibs[len(fibs)-2])
+	}
+
+	code := make([]bool, len(fibs))
+	n := 0
+	for i := len(fibs) - 1; i >= 0; i-- {
+		if fibs[i] <= v {
+			code[i] = true
+			v -= fibs[i]
+			if n == 0 {
+				n = i + 1
+			}
+		}
+	}
+	return append(code[:n], true)
+}
+
+// Reads n fields of bitsPerField bits
+func (r *TCEncoder) ReadNBitField(n int, bitsPerField uint) []int {
+	var ret = make([]int, n)
+	for i := 0; i < n; i++ {
+		ret[i] = r.ReadInt(bitsPerField)
+	}
+	return ret
+}
+
+// Writes n fields of bitsPerField bits, missing values being written as 0
+func (r *TCEncoder) WriteNBitField(values []int, n int, bitsPerField uint) {
+	for i := 0; i < n; i++ {
+		v := 0
+		if i < len(values) {
+			v = values[i]
+		}
+		r.WriteInt(v, bitsPerField)
+	}
+}
@@ -0,0 +1,112 @@
+package iabtcfv2
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// V1ConsentData is a TCF v1.1 consent string
+// In range encoding, vendors in RangeEntries have the opposite of DefaultConsent,
+// and other vendors up to MaxVendorId have DefaultConsent
+type V1ConsentData struct {
+	Version           int
+	Created           time.Time
+	LastUpdated       time.Time
+	CmpId             int
+	CmpVersion        int
+	ConsentScreen     int
+	ConsentLanguage   string
+	VendorListVersion int
+	PurposesAllowed   map[int]bool
+	MaxVendorId       int
+	IsRangeEncoding   bool
+	VendorsAllowed    map[int]bool
+	VendorsAllowedSet *IDSet
+	DefaultConsent    bool
+	NumEntries        int
+	RangeEntries      []*RangeEntry
+}
+
+// Decodes a TCF v1.1 consent string and returns it as a V1ConsentData structure
+func DecodeV1(s string) (c *V1ConsentData, err error) {
+	// v1.1 strings were sometimes produced with base64 padding
+	d, err := newSegmentDecoder(SegmentTypeUndefined, strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+
+	c = d.decodeV1ConsentData()
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return c, nil
+}
+
+func (d *segmentDecoder) decodeV1ConsentData() *V1ConsentData {
+	c := &V1ConsentData{}
+	c.Version = d.readInt("Version", bitsVersion)
+	if d.err == nil && c.Version != int(TcfVersion1) {
+		d.err = &DecodeError{SegmentType: d.segmentType, Field: "Version", Err: fmt.Errorf("%w: %d", ErrUnsupportedVersion, c.Version)}
+		return c
+	}
+	c.Created = d.readTime("Created")
+	c.LastUpdated = d.readTime("LastUpdated")
+	c.CmpId = d.readInt("CmpId", bitsCmpId)
+	c.CmpVersion = d.readInt("CmpVersion", bitsCmpVersion)
+	c.ConsentScreen = d.readInt("ConsentScreen", bitsConsentScreen)
+	c.ConsentLanguage = d.readChars("ConsentLanguage", bitsConsentLanguage)
+	c.VendorListVersion = d.readInt("VendorListVersion", bitsVendorListVersion)
+	c.PurposesAllowed = d.readBitField("PurposesAllowed", bitsV1PurposesAllowed)
+
+	c.MaxVendorId = d.readInt("MaxVendorId", bitsMaxVendorId)
+	c.IsRangeEncoding = d.readInt("EncodingType", bitsV1EncodingType) == 1
+	if c.IsRangeEncoding {
+		c.DefaultConsent = d.readBool("DefaultConsent")
+		c.NumEntries, c.RangeEntries = d.readRangeEntries("RangeEntries")
+	} else {
+		c.VendorsAllowed = d.readBitField("VendorsAllowed", uint(c.MaxVendorId))
+	}
+
+	if d.err == nil {
+		c.VendorsAllowedSet = c.vendorsAllowedSet()
+	}
+
+	return c
+}
+
+// Returns the set of vendors IsVendorAllowed returns true for
+// Range entries list the exceptions to DefaultConsent among vendors 1 to MaxVendorId
+func (c *V1ConsentData) vendorsAllowedSet() *IDSet {
+	all := &IDSet{}
+	all.addRange(1, c.MaxVendorId)
+	listed := newIDSetFromEncoding(c.IsRangeEncoding, c.VendorsAllowed, c.RangeEntries)
+	if c.IsRangeEncoding && c.DefaultConsent {
+		return all.difference(listed)
+	}
+	return all.Intersect(listed)
+}
+
+// Returns true if user has given consent to purpose id
+func (c *V1ConsentData) IsPurposeAllowed(id int) bool {
+	return c.PurposesAllowed[id]
+}
+
+// Returns true if user has given consent to vendor id
+func (c *V1ConsentData) IsVendorAllowed(id int) bool {
+	if id < 1 || id > c.MaxVendorId {
+		return false
+	}
+
+	if c.IsRangeEncoding {
+		for _, entry := range c.RangeEntries {
+			if entry.StartVendorID <= id && id <= entry.EndVendorID {
+				return !c.DefaultConsent
+			}
+		}
+		return c.DefaultConsent
+	}
+
+	return c.VendorsAllowed[id]
+}
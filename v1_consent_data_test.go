@@ -0,0 +1,87 @@
+package iabtcfv2
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecodeV1(t *testing.T) {
+	str := "BOEFEAyOEFEAyAHABDENAI4AAAB9vABAASA"
+
+	data, err := DecodeV1(str)
+	if err != nil {
+		t.Errorf("Consent string should be decoded without error: %s", err)
+		return
+	}
+
+	if data.Version != 1 || data.CmpId != 7 || data.CmpVersion != 1 || data.ConsentScreen != 3 ||
+		data.ConsentLanguage != "EN" || data.VendorListVersion != 8 {
+		t.Errorf("Unexpected consent data: %+v", data)
+	}
+
+	if !data.IsPurposeAllowed(1) || !data.IsPurposeAllowed(2) || !data.IsPurposeAllowed(3) || data.IsPurposeAllowed(4) {
+		t.Errorf("Purposes 1, 2 and 3 should be allowed")
+	}
+
+	if !data.IsRangeEncoding || !data.DefaultConsent || data.MaxVendorId != 2011 || data.NumEntries != 1 {
+		t.Errorf("Vendors should be range encoded with default consent")
+		return
+	}
+
+	if data.IsVendorAllowed(9) || !data.IsVendorAllowed(1) || !data.IsVendorAllowed(2011) || data.IsVendorAllowed(2012) || data.IsVendorAllowed(0) {
+		t.Errorf("All vendors up to 2011 but 9 should be allowed")
+	}
+
+	if data.VendorsAllowedSet.Len() != 2010 || data.VendorsAllowedSet.Contains(9) {
+		t.Errorf("Vendors set should contain allowed vendors")
+	}
+}
+
+func TestDecodeV1BitField(t *testing.T) {
+	str := "BOr70tQOxPQw-BcAsCFRDEqAAAAu1rxyZn7kfUXiXSZxNuiGGp6h-Wd9CWUcKZYpMAnyhYZRfg_AQhQ4Eu0LRNNycgh45MoCCMoRQaiSkCABGgFcTpjTmxAUxoRLawAMBrwhWLEQeroyHcJzAAHN_QjACAA"
+
+	data, err := DecodeV1(str)
+	if err != nil {
+		t.Errorf("Consent string should be decoded without error: %s", err)
+		return
+	}
+
+	if data.IsRangeEncoding || data.MaxVendorId == 0 {
+		t.Errorf("Vendors should be bit field encoded")
+		return
+	}
+
+	for id := 0; id <= data.MaxVendorId+1; id++ {
+		if data.IsVendorAllowed(id) != data.VendorsAllowed[id] || data.VendorsAllowedSet.Contains(id) != data.VendorsAllowed[id] {
+			t.Errorf("Vendor %d should match the bit field", id)
+			return
+		}
+	}
+}
+
+func TestDecodeV1Errors(t *testing.T) {
+	_, err := DecodeV1("COxR03kOxR1CqBcABCENAgCMAP_AAH_AAAqIF3EXySoGY2thI2YVFxBEIYwfJxyigMgChgQIsSwNQIeFLBoGLiAAHBGYJAQAGBAEEACBAQIkHGBMCQAAgAgBiRCMQEGMCzNIBIBAggEbY0FACCVmHkHSmZCY7064O__QLuIJEFQMAkSBAIACLECIQwAQDiAAAYAlAAABAhIaAAgIWBQEeAAAACAwAAgAAABBAAACAAQAAICIAAABAAAgAiAQAAAAGgIQAACBABACRIAAAEANCAAgiCEAQg4EAo4AAA")
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("TCF v2 string should not be decoded: %v", err)
+	}
+
+	_, err = DecodeV1("BOEFEAyOEFEAyAHABDENAI4AAAB9vABA")
+	if !errors.Is(err, ErrTruncated) {
+		t.Errorf("Truncated string should not be decoded: %v", err)
+	}
+}
+
+func TestV1VendorsAllowedSet(t *testing.T) {
+	for _, c := range []*V1ConsentData{
+		{MaxVendorId: 200, IsRangeEncoding: true, DefaultConsent: true, RangeEntries: []*RangeEntry{{StartVendorID: 10, EndVendorID: 70}, {StartVendorID: 190, EndVendorID: 300}}},
+		{MaxVendorId: 200, IsRangeEncoding: true, RangeEntries: []*RangeEntry{{StartVendorID: 10, EndVendorID: 70}, {StartVendorID: 190, EndVendorID: 300}}},
+		{MaxVendorId: 20, VendorsAllowed: map[int]bool{1: true, 5: true, 30: true}},
+	} {
+		s := c.vendorsAllowedSet()
+		for id := 0; id <= 310; id++ {
+			if s.Contains(id) != c.IsVendorAllowed(id) {
+				t.Errorf("Vendors set should contain vendor %d if it is allowed: %+v", id, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package iabtcfv2
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Maps each TCF v2 purpose to the TCF v1.1 purposes that must all be allowed to grant it, which must not be changed
+// - v1 purpose 1: Information storage and access
+// - v1 purpose 2: Personalisation
+// - v1 purpose 3: Ad selection, delivery, reporting
+// - v1 purpose 4: Content selection, delivery, reporting
+// - v1 purpose 5: Measurement
+var defaultV1PurposeMapping = map[int][]int{
+	1:  {1},
+	2:  {3},
+	3:  {2},
+	4:  {2, 3},
+	5:  {2},
+	6:  {2, 4},
+	7:  {3, 5},
+	8:  {4, 5},
+	9:  {5},
+	10: {5},
+}
+
+// Returns a copy of the default mapping of each TCF v2 purpose to the TCF v1.1 purposes that must all be allowed to grant it,
+// that can be changed and set as V1ConversionOptions.PurposeMapping
+func DefaultV1PurposeMapping() map[int][]int {
+	m := make(map[int][]int, len(defaultV1PurposeMapping))
+	for purposeId, v1PurposeIds := range defaultV1PurposeMapping {
+		m[purposeId] = append([]int(nil), v1PurposeIds...)
+	}
+	return m
+}
+
+type V1ConversionOptions struct {
+	CmpId             int
+	CmpVersion        int
+	VendorListVersion int
+	// Defaults to 2 when 0
+	TcfPolicyVersion int
+	// Defaults to "AA" when empty
+	PublisherCC string
+	// Defaults to the mapping returned by DefaultV1PurposeMapping when nil
+	PurposeMapping map[int][]int
+}
+
+// V1ConversionIssue is a piece of information of a TCF v1.1 consent string that couldn't be carried over
+type V1ConversionIssue struct {
+	Field  string
+	Reason string
+}
+
+// V1ConversionReport lists the information lost when converting a TCF v1.1 consent string
+type V1ConversionReport struct {
+	Issues []*V1ConversionIssue
+}
+
+func (r *V1ConversionReport) add(field string, format string, a ...interface{}) {
+	r.Issues = append(r.Issues, &V1ConversionIssue{Field: field, Reason: fmt.Sprintf(format, a...)})
+}
+
+// Decodes a TCF v1.1 consent string and converts it to a TCData structure, see V1ConsentData.ToTCData
+func ConvertV1(s string, opts V1ConversionOptions) (*TCData, *V1ConversionReport, error) {
+	c, err := DecodeV1(s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c.ToTCData(opts)
+}
+
+// Converts the consent data to a TCData structure with a TCF v2 Core String
+// A v2 purpose is allowed if all the v1 purposes it is mapped to are allowed, and consented vendors are kept as is
+// CMP, vendor list and policy versions are taken from opts
+// Legitimate interest, special features and publisher restrictions don't exist in v1.1 and are left empty
+// The Core String is encoded and decoded again, so that the TCData is the same as if it was read from a TC String
+func (c *V1ConsentData) ToTCData(opts V1ConversionOptions) (*TCData, *V1ConversionReport, error) {
+	if opts.TcfPolicyVersion == 0 {
+		opts.TcfPolicyVersion = 2
+	}
+	if opts.PublisherCC == "" {
+		opts.PublisherCC = "AA"
+	}
+	if opts.PurposeMapping == nil {
+		opts.PurposeMapping = defaultV1PurposeMapping
+	}
+
+	r := &V1ConversionReport{}
+	if c.CmpId != opts.CmpId {
+		r.add("CmpId", "replaced %d by %d", c.CmpId, opts.CmpId)
+	}
+	if c.CmpVersion != opts.CmpVersion {
+		r.add("CmpVersion", "replaced %d by %d", c.CmpVersion, opts.CmpVersion)
+	}
+	if c.VendorListVersion != opts.VendorListVersion {
+		r.add("VendorListVersion", "replaced %d by %d", c.VendorListVersion, opts.VendorListVersion)
+	}
+
+	core := &CoreString{
+		Version:                int(TcfVersion2),
+		Created:                c.Created,
+		LastUpdated:            c.LastUpdated,
+		CmpId:                  opts.CmpId,
+		CmpVersion:             opts.CmpVersion,
+		ConsentScreen:          c.ConsentScreen,
+		ConsentLanguage:        c.ConsentLanguage,
+		VendorListVersion:      opts.VendorListVersion,
+		TcfPolicyVersion:       opts.TcfPolicyVersion,
+		PublisherCC:            opts.PublisherCC,
+		SpecialFeatureOptIns:   map[int]bool{},
+		PurposesConsent:        map[int]bool{},
+		PurposesLITransparency: map[int]bool{},
+	}
+
+	mapped := map[int]bool{}
+	for purposeId, v1PurposeIds := range opts.PurposeMapping {
+		allowed := len(v1PurposeIds) > 0
+		for _, id := range v1PurposeIds {
+			allowed = allowed && c.IsPurposeAllowed(id)
+			mapped[id] = true
+		}
+		if allowed {
+			core.PurposesConsent[purposeId] = true
+		}
+	}
+
+	var unmapped []int
+	for id, allowed := range c.PurposesAllowed {
+		if allowed && !mapped[id] {
+			unmapped = append(unmapped, id)
+		}
+	}
+	sort.Ints(unmapped)
+	for _, id := range unmapped {
+		r.add("PurposesAllowed", "purpose %d has no v2 equivalent", id)
+	}
+
+	var vendorIds []int
+	for id := 1; id <= c.MaxVendorId; id++ {
+		if c.IsVendorAllowed(id) {
+			vendorIds = append(vendorIds, id)
+		}
+	}
+	core.SetVendorsConsent(vendorIds...)
+
+	decoded, err := DecodeCoreString(core.Encode())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &TCData{CoreString: decoded}, r, nil
+}
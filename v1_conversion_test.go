@@ -0,0 +1,90 @@
+package iabtcfv2
+
+import (
+	"testing"
+)
+
+func TestConvertV1(t *testing.T) {
+	str := "BOEFEAyOEFEAyAHABDENAI4AAAB9vABAASA"
+
+	data, report, err := ConvertV1(str, V1ConversionOptions{CmpId: 92, CmpVersion: 2, VendorListVersion: 150})
+	if err != nil {
+		t.Errorf("Consent string should be converted without error: %s", err)
+		return
+	}
+
+	c := data.CoreString
+	if c.Version != 2 || c.CmpId != 92 || c.CmpVersion != 2 || c.VendorListVersion != 150 || c.TcfPolicyVersion != 2 ||
+		c.ConsentLanguage != "EN" || c.PublisherCC != "AA" || c.ConsentScreen != 3 {
+		t.Errorf("Unexpected core string: %+v", c)
+	}
+
+	// v1 purposes 1, 2 and 3 are allowed
+	for _, id := range []int{1, 2, 3, 4, 5} {
+		if !c.IsPurposeAllowed(id) {
+			t.Errorf("Purpose %d should be allowed", id)
+		}
+	}
+	for _, id := range []int{6, 7, 8, 9, 10} {
+		if c.IsPurposeAllowed(id) {
+			t.Errorf("Purpose %d should not be allowed", id)
+		}
+	}
+
+	if c.IsVendorAllowed(9) || !c.IsVendorAllowed(8) || !c.IsVendorAllowed(2011) || c.IsVendorAllowed(2012) {
+		t.Errorf("All vendors up to 2011 but 9 should be allowed")
+	}
+
+	decoded, err := Decode(data.ToTCString())
+	if err != nil || decoded.CoreString.Encode() != c.Encode() {
+		t.Errorf("Converted TC String should be decoded: %v", err)
+	}
+
+	fields := map[string]bool{}
+	for _, issue := range report.Issues {
+		fields[issue.Field] = true
+	}
+	if len(report.Issues) != 3 || !fields["CmpId"] || !fields["CmpVersion"] || !fields["VendorListVersion"] {
+		t.Errorf("Report should list the replaced values: %+v", report.Issues)
+	}
+}
+
+func TestConvertV1PurposeMapping(t *testing.T) {
+	v1 := &V1ConsentData{
+		Version:         1,
+		Created:         timeFromDeciSeconds(16431552000),
+		LastUpdated:     timeFromDeciSeconds(16431552000),
+		CmpId:           7,
+		ConsentLanguage: "FR",
+		PurposesAllowed: map[int]bool{1: true, 5: true, 6: true},
+		MaxVendorId:     3,
+		VendorsAllowed:  map[int]bool{1: true, 3: true},
+	}
+
+	data, report, err := v1.ToTCData(V1ConversionOptions{CmpId: 7, PurposeMapping: map[int][]int{1: {1}, 9: {5}}})
+	if err != nil {
+		t.Errorf("Consent data should be converted without error: %s", err)
+		return
+	}
+
+	if !data.IsPurposeAllowed(1) || !data.IsPurposeAllowed(9) || data.IsPurposeAllowed(2) || data.IsPurposeAllowed(10) {
+		t.Errorf("Only purposes 1 and 9 should be allowed")
+	}
+
+	if !data.IsVendorAllowed(1) || data.IsVendorAllowed(2) || !data.IsVendorAllowed(3) {
+		t.Errorf("Vendors 1 and 3 should be allowed")
+	}
+
+	if len(report.Issues) != 1 || report.Issues[0].Field != "PurposesAllowed" {
+		t.Errorf("Report should list the unmapped purpose 6: %+v", report.Issues)
+	}
+
+	// Changing the returned default mapping doesn't change the conversion of others
+	mapping := DefaultV1PurposeMapping()
+	mapping[1] = []int{6}
+	mapping[2][0] = 6
+	data, _, err = v1.ToTCData(V1ConversionOptions{CmpId: 7})
+	if err != nil || !data.IsPurposeAllowed(1) || data.IsPurposeAllowed(2) || DefaultV1PurposeMapping()[2][0] != 3 {
+		t.Errorf("Default purpose mapping should not be changed through a returned copy: %v", err)
+	}
+}
@@ -0,0 +1,255 @@
+package iabtcfv2
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ValidationSeverity int
+
+const (
+	// The TC String can be used but a value is unusual
+	ValidationSeverityWarning ValidationSeverity = 0
+	// A value violates the TCF policy
+	ValidationSeverityError ValidationSeverity = 1
+)
+
+// ValidationCode identifies the rule a ValidationIssue breaks
+type ValidationCode string
+
+const (
+	ValidationCodeUnsupportedVersion        ValidationCode = "unsupportedVersion"
+	ValidationCodeCreatedInFuture           ValidationCode = "createdInFuture"
+	ValidationCodeCreatedTooEarly           ValidationCode = "createdTooEarly"
+	ValidationCodeLastUpdatedInFuture       ValidationCode = "lastUpdatedInFuture"
+	ValidationCodeLastUpdatedTooEarly       ValidationCode = "lastUpdatedTooEarly"
+	ValidationCodeLastUpdatedBeforeCreated  ValidationCode = "lastUpdatedBeforeCreated"
+	ValidationCodeInvalidConsentLanguage    ValidationCode = "invalidConsentLanguage"
+	ValidationCodeInvalidPublisherCC        ValidationCode = "invalidPublisherCC"
+	ValidationCodeUnknownPolicyVersion      ValidationCode = "unknownPolicyVersion"
+	ValidationCodeLegitimateInterestPurpose ValidationCode = "legitimateInterestPurpose"
+	ValidationCodeUndefinedSpecialFeature   ValidationCode = "undefinedSpecialFeature"
+	ValidationCodeUndefinedPurpose          ValidationCode = "undefinedPurpose"
+	ValidationCodeVendorIdAboveMax          ValidationCode = "vendorIdAboveMax"
+	ValidationCodeInvertedRange             ValidationCode = "invertedRange"
+	ValidationCodeOverlappingRanges         ValidationCode = "overlappingRanges"
+	ValidationCodeDuplicatePubRestriction   ValidationCode = "duplicatePubRestriction"
+	ValidationCodeUndefinedRestrictionType  ValidationCode = "undefinedRestrictionType"
+)
+
+// ValidationIssue is a value of a TCData that breaks the TCF policy
+// Field is the name of the structure field holding the value, and Id the purpose, feature or vendor id if any
+type ValidationIssue struct {
+	Code     ValidationCode     `json:"code"`
+	Severity ValidationSeverity `json:"severity"`
+	Field    string             `json:"field"`
+	Id       int                `json:"id,omitempty"`
+	Message  string             `json:"message"`
+}
+
+type ValidationOptions struct {
+	// Defaults to time.Now when zero
+	Now time.Time
+	// Created and LastUpdated can be up to MaxClockSkew after Now
+	MaxClockSkew time.Duration
+	// Overrides the rules of TcfPolicyVersion when not nil
+	PolicyRules *PolicyRules
+}
+
+const (
+	maxSpecialFeatureId = 2
+	maxPurposeId        = 11
+)
+
+// Earliest Created and LastUpdated of a TCF v2 TC String
+var minValidationTime = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Policy versions of TCF v2
+var knownTcfPolicyVersions = map[int]bool{2: true, 3: true, 4: true, 5: true}
+
+// Returns the issues found in t, none if t or its Core String is nil
+// Unlike decoding, which only reads the bits, values are checked against the TCF policy
+func Validate(t *TCData, opts ValidationOptions) []*ValidationIssue {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+
+	v := &validator{}
+	if t == nil || t.CoreString == nil {
+		return v.issues
+	}
+
+	c := t.CoreString
+	if c.Version != int(TcfVersion2) {
+		v.add(ValidationCodeUnsupportedVersion, ValidationSeverityError, "Version", 0, "version %d isn't 2", c.Version)
+	}
+
+	latest := opts.Now.Add(opts.MaxClockSkew)
+	for _, f := range []struct {
+		field    string
+		value    time.Time
+		future   ValidationCode
+		tooEarly ValidationCode
+	}{
+		{"Created", c.Created, ValidationCodeCreatedInFuture, ValidationCodeCreatedTooEarly},
+		{"LastUpdated", c.LastUpdated, ValidationCodeLastUpdatedInFuture, ValidationCodeLastUpdatedTooEarly},
+	} {
+		if f.value.After(latest) {
+			v.add(f.future, ValidationSeverityWarning, f.field, 0, "%s is in the future", f.value.Format(time.RFC3339))
+		}
+		if f.value.Before(minValidationTime) {
+			v.add(f.tooEarly, ValidationSeverityWarning, f.field, 0, "%s is before %s", f.value.Format(time.RFC3339), minValidationTime.Format(time.RFC3339))
+		}
+	}
+	if c.LastUpdated.Before(c.Created) {
+		v.add(ValidationCodeLastUpdatedBeforeCreated, ValidationSeverityWarning, "LastUpdated", 0, "%s is before created %s", c.LastUpdated.Format(time.RFC3339), c.Created.Format(time.RFC3339))
+	}
+
+	if !isoLanguageCodes[strings.ToLower(c.ConsentLanguage)] {
+		v.add(ValidationCodeInvalidConsentLanguage, ValidationSeverityWarning, "ConsentLanguage", 0, "%q isn't an ISO 639-1 code", c.ConsentLanguage)
+	}
+	// "AA" is the country code of an unknown publisher country
+	if c.PublisherCC != "AA" && !isoCountryCodes[strings.ToUpper(c.PublisherCC)] {
+		v.add(ValidationCodeInvalidPublisherCC, ValidationSeverityWarning, "PublisherCC", 0, "%q isn't an ISO 3166-1 alpha-2 code", c.PublisherCC)
+	}
+	if !knownTcfPolicyVersions[c.TcfPolicyVersion] {
+		v.add(ValidationCodeUnknownPolicyVersion, ValidationSeverityWarning, "TcfPolicyVersion", 0, "unknown policy version %d", c.TcfPolicyVersion)
+	}
+
+	rules := opts.PolicyRules
+	if rules == nil {
+		rules = policyRulesForVersion(c.TcfPolicyVersion)
+	}
+	for _, id := range bitFieldIDs(c.PurposesLITransparency) {
+		if !rules.AllowsLegitimateInterest(id) {
+			v.add(ValidationCodeLegitimateInterestPurpose, ValidationSeverityError, fieldPurposesLITransparency, id, "purpose %d can't rely on legitimate interest under policy version %d", id, c.TcfPolicyVersion)
+		}
+	}
+	v.undefinedIDs(ValidationCodeUndefinedSpecialFeature, fieldSpecialFeatureOptIns, c.SpecialFeatureOptIns, maxSpecialFeatureId)
+	v.undefinedIDs(ValidationCodeUndefinedPurpose, fieldPurposesConsent, c.PurposesConsent, maxPurposeId)
+	v.undefinedIDs(ValidationCodeUndefinedPurpose, fieldPurposesLITransparency, c.PurposesLITransparency, maxPurposeId)
+
+	v.vendors(fieldVendorsConsent, c.MaxVendorId, c.IsRangeEncoding, c.VendorsConsent, c.RangeEntries)
+	v.vendors(fieldVendorsLITransparency, c.MaxVendorIdLI, c.IsRangeEncodingLI, c.VendorsLITransparency, c.RangeEntriesLI)
+	v.pubRestrictions(c.PubRestrictions)
+
+	if d := t.DisclosedVendors; d != nil {
+		v.vendors(fieldDisclosedVendors, d.MaxVendorId, d.IsRangeEncoding, d.DisclosedVendors, d.RangeEntries)
+	}
+	if a := t.AllowedVendors; a != nil {
+		v.vendors(fieldAllowedVendors, a.MaxVendorId, a.IsRangeEncoding, a.AllowedVendors, a.RangeEntries)
+	}
+
+	return v.issues
+}
+
+// Returns true if one of issues is an error
+func HasValidationErrors(issues []*ValidationIssue) bool {
+	for _, i := range issues {
+		if i.Severity == ValidationSeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+type validator struct {
+	issues []*ValidationIssue
+}
+
+func (v *validator) add(code ValidationCode, severity ValidationSeverity, field string, id int, format string, a ...interface{}) {
+	v.issues = append(v.issues, &ValidationIssue{Code: code, Severity: severity, Field: field, Id: id, Message: fmt.Sprintf(format, a...)})
+}
+
+func (v *validator) undefinedIDs(code ValidationCode, field string, bitField map[int]bool, max int) {
+	for _, id := range bitFieldIDs(bitField) {
+		if id > max {
+			v.add(code, ValidationSeverityWarning, field, id, "id %d isn't defined", id)
+		}
+	}
+}
+
+func (v *validator) vendors(field string, maxVendorId int, isRangeEncoding bool, bitField map[int]bool, entries []*RangeEntry) {
+	if !isRangeEncoding {
+		if max := newIDSetFromEncoding(false, bitField, nil).Max(); max > maxVendorId {
+			v.add(ValidationCodeVendorIdAboveMax, ValidationSeverityError, field, max, "vendor id %d above max vendor id %d", max, maxVendorId)
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.EndVendorID > maxVendorId {
+			v.add(ValidationCodeVendorIdAboveMax, ValidationSeverityError, field, entry.EndVendorID, "vendor id %d above max vendor id %d", entry.EndVendorID, maxVendorId)
+			break
+		}
+	}
+	v.rangeEntries(field, entries)
+}
+
+// Checks that range entries are ordered and disjoint
+func (v *validator) rangeEntries(field string, entries []*RangeEntry) {
+	sorted := make([]*RangeEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.EndVendorID < entry.StartVendorID {
+			v.add(ValidationCodeInvertedRange, ValidationSeverityError, field, entry.StartVendorID, "range %d-%d ends before it starts", entry.StartVendorID, entry.EndVendorID)
+			continue
+		}
+		sorted = append(sorted, entry)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].StartVendorID < sorted[j].StartVendorID
+	})
+	for i := 1; i < len(sorted); i++ {
+		prev, entry := sorted[i-1], sorted[i]
+		if entry.StartVendorID <= prev.EndVendorID {
+			v.add(ValidationCodeOverlappingRanges, ValidationSeverityWarning, field, entry.StartVendorID, "range %d-%d overlaps range %d-%d", entry.StartVendorID, entry.EndVendorID, prev.StartVendorID, prev.EndVendorID)
+		}
+	}
+}
+
+// Checks that a vendor has at most one restriction per purpose
+func (v *validator) pubRestrictions(restrictions []*PubRestriction) {
+	purposeVendors := make(map[int]*IDSet)
+	for _, r := range restrictions {
+		if r.RestrictionType < RestrictionTypeNotAllowed || r.RestrictionType >= RestrictionTypeUndefined {
+			v.add(ValidationCodeUndefinedRestrictionType, ValidationSeverityError, fieldPubRestrictions, r.PurposeId, "restriction type %d of purpose %d is undefined", int(r.RestrictionType), r.PurposeId)
+		}
+		v.rangeEntries(fieldPubRestrictions, r.RangeEntries)
+
+		vendors := newIDSetFromEncoding(true, nil, r.RangeEntries)
+		if both := purposeVendors[r.PurposeId].Intersect(vendors); both.Len() > 0 {
+			v.add(ValidationCodeDuplicatePubRestriction, ValidationSeverityError, fieldPubRestrictions, r.PurposeId, "purpose %d has several restrictions for vendors %s", r.PurposeId, joinIDs(both.IDs()))
+		}
+		purposeVendors[r.PurposeId] = purposeVendors[r.PurposeId].Union(vendors)
+	}
+}
+
+// ISO 639-1 language codes
+var isoLanguageCodes = newCodeSet("" +
+	"aa ab ae af ak am an ar as av ay az ba be bg bh bi bm bn bo br bs ca ce ch co cr cs cu cv cy " +
+	"da de dv dz ee el en eo es et eu fa ff fi fj fo fr fy ga gd gl gn gu gv ha he hi ho hr ht hu hy hz " +
+	"ia id ie ig ii ik io is it iu ja jv ka kg ki kj kk kl km kn ko kr ks ku kv kw ky la lb lg li ln lo lt lu lv " +
+	"mg mh mi mk ml mn mr ms mt my na nb nd ne ng nl nn no nr nv ny oc oj om or os pa pi pl ps pt qu " +
+	"rm rn ro ru rw sa sc sd se sg si sk sl sm sn so sq sr ss st su sv sw ta te tg th ti tk tl tn to tr ts tt tw ty " +
+	"ug uk ur uz ve vi vo wa wo xh yi yo za zh zu")
+
+// ISO 3166-1 alpha-2 country codes
+var isoCountryCodes = newCodeSet("" +
+	"AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS BT BV BW BY BZ " +
+	"CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ DE DJ DK DM DO DZ EC EE EG EH ER ES ET FI FJ FK FM FO FR " +
+	"GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY HK HM HN HR HT HU ID IE IL IM IN IO IQ IR IS IT " +
+	"JE JM JO JP KE KG KH KI KM KN KP KR KW KY KZ LA LB LC LI LK LR LS LT LU LV LY MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW MX MY MZ " +
+	"NA NC NE NF NG NI NL NO NP NR NU NZ OM PA PE PF PG PH PK PL PM PN PR PS PT PW PY QA RE RO RS RU RW " +
+	"SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV SX SY SZ TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ " +
+	"UA UG UM US UY UZ VA VC VE VG VI VN VU WF WS YE YT ZA ZM ZW")
+
+func newCodeSet(codes string) map[string]bool {
+	m := make(map[string]bool)
+	for _, code := range strings.Fields(codes) {
+		m[code] = true
+	}
+	return m
+}
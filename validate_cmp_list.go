@@ -0,0 +1,28 @@
+package iabtcfv2
+
+import "github.com/SirDataFR/iabtcfv2/gvl"
+
+const (
+	ValidationCodeUnknownCMP ValidationCode = "unknownCMP"
+	ValidationCodeDeletedCMP ValidationCode = "deletedCMP"
+)
+
+// Returns the issues found in t against the CMPs registered in l
+// As required by the TCF policy, a TC String is rejected if its CmpId isn't in the list,
+// or if it was last updated after the CMP was deleted
+func ValidateCMPList(t *TCData, l *gvl.CMPList) []*ValidationIssue {
+	v := &validator{}
+	if t == nil || t.CoreString == nil || l == nil {
+		return v.issues
+	}
+
+	c := t.CoreString
+	cmp := l.Lookup(c.CmpId)
+	if cmp == nil {
+		v.add(ValidationCodeUnknownCMP, ValidationSeverityError, "CmpId", c.CmpId, "CMP %d isn't in the CMP list", c.CmpId)
+	} else if cmp.IsDeletedAt(c.LastUpdated) {
+		v.add(ValidationCodeDeletedCMP, ValidationSeverityError, "CmpId", c.CmpId, "CMP %d was deleted on %s", c.CmpId, cmp.DeletedDate.Format("2006-01-02"))
+	}
+
+	return v.issues
+}
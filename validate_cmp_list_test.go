@@ -0,0 +1,38 @@
+package iabtcfv2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SirDataFR/iabtcfv2/gvl"
+)
+
+func TestValidateCMPList(t *testing.T) {
+	l, err := gvl.LoadCMPListFile("gvl/testdata/cmp-list.json")
+	if err != nil {
+		t.Fatalf("CMP list should be loaded without error: %s", err)
+	}
+
+	for _, tc := range []struct {
+		cmpId       int
+		lastUpdated time.Time
+		code        ValidationCode
+	}{
+		{92, time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC), ""},
+		{300, time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC), ""},
+		{300, time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC), ValidationCodeDeletedCMP},
+		{1, time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC), ValidationCodeUnknownCMP},
+	} {
+		data := &TCData{CoreString: &CoreString{CmpId: tc.cmpId, LastUpdated: tc.lastUpdated}}
+		issues := ValidateCMPList(data, l)
+		if tc.code == "" {
+			if len(issues) > 0 {
+				t.Errorf("CMP %d should be valid on %s: %+v", tc.cmpId, tc.lastUpdated, issues[0])
+			}
+			continue
+		}
+		if len(issues) != 1 || issues[0].Code != tc.code || issues[0].Severity != ValidationSeverityError {
+			t.Errorf("CMP %d should be rejected with %s on %s: %+v", tc.cmpId, tc.code, tc.lastUpdated, issues)
+		}
+	}
+}
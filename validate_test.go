@@ -0,0 +1,100 @@
+package iabtcfv2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SirDataFR/iabtcfv2/gvl"
+)
+
+func TestValidate(t *testing.T) {
+	data, err := NewTCDataBuilder().
+		WithCMP(92, 3).
+		WithGVLVersion(150).
+		WithConsentLanguage("fr").
+		WithPublisherCC("fr").
+		WithCreated(timeFromDeciSeconds(16431552000)).
+		ConsentPurposes(1, 2, 3).
+		LIPurposes(2, 7).
+		ConsentVendors(1, 2, 755).
+		AddPubRestriction(2, RestrictionTypeRequireConsent, 8, 9).
+		Build()
+	if err != nil {
+		t.Fatalf("TCData should be built without error: %s", err)
+	}
+
+	opts := ValidationOptions{Now: time.Date(2022, time.February, 1, 0, 0, 0, 0, time.UTC)}
+	if issues := Validate(data, opts); len(issues) > 0 {
+		t.Errorf("Valid TCData should have no issues: %+v", issues[0])
+	}
+
+	c := data.CoreString
+	c.Version = 1
+	c.Created = time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC)
+	c.LastUpdated = time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	c.ConsentLanguage = "XX"
+	c.PublisherCC = "ZZ"
+	c.TcfPolicyVersion = 9
+	c.SpecialFeatureOptIns = map[int]bool{3: true}
+	c.PurposesLITransparency = map[int]bool{1: true, 4: true}
+	c.MaxVendorId = 10
+	c.IsRangeEncoding = false
+	c.VendorsConsent = map[int]bool{1: true, 12: true}
+	c.IsRangeEncodingLI = true
+	c.MaxVendorIdLI = 20
+	c.RangeEntriesLI = []*RangeEntry{{StartVendorID: 5, EndVendorID: 3}, {StartVendorID: 8, EndVendorID: 12}, {StartVendorID: 10, EndVendorID: 15}}
+	c.PubRestrictions = append(c.PubRestrictions, &PubRestriction{PurposeId: 2, RestrictionType: RestrictionTypeNotAllowed, RangeEntries: []*RangeEntry{{StartVendorID: 9, EndVendorID: 9}}})
+
+	expected := []struct {
+		code     ValidationCode
+		severity ValidationSeverity
+		id       int
+	}{
+		{ValidationCodeUnsupportedVersion, ValidationSeverityError, 0},
+		{ValidationCodeCreatedTooEarly, ValidationSeverityWarning, 0},
+		{ValidationCodeLastUpdatedInFuture, ValidationSeverityWarning, 0},
+		{ValidationCodeInvalidConsentLanguage, ValidationSeverityWarning, 0},
+		{ValidationCodeInvalidPublisherCC, ValidationSeverityWarning, 0},
+		{ValidationCodeUnknownPolicyVersion, ValidationSeverityWarning, 0},
+		{ValidationCodeLegitimateInterestPurpose, ValidationSeverityError, 1},
+		{ValidationCodeLegitimateInterestPurpose, ValidationSeverityError, 4},
+		{ValidationCodeUndefinedSpecialFeature, ValidationSeverityWarning, 3},
+		{ValidationCodeVendorIdAboveMax, ValidationSeverityError, 12},
+		{ValidationCodeInvertedRange, ValidationSeverityError, 5},
+		{ValidationCodeOverlappingRanges, ValidationSeverityWarning, 10},
+		{ValidationCodeDuplicatePubRestriction, ValidationSeverityError, 2},
+	}
+
+	issues := Validate(data, opts)
+	if len(issues) != len(expected) {
+		for _, i := range issues {
+			t.Logf("%+v", i)
+		}
+		t.Fatalf("Expected %d issues, got %d", len(expected), len(issues))
+	}
+	for i, e := range expected {
+		if issues[i].Code != e.code || issues[i].Severity != e.severity || issues[i].Id != e.id {
+			t.Errorf("Unexpected issue %d: %+v", i, issues[i])
+		}
+	}
+	if !HasValidationErrors(issues) {
+		t.Errorf("Issues should contain errors")
+	}
+}
+
+func TestValidateNil(t *testing.T) {
+	vl, err := gvl.LoadFile("gvl/testdata/vendor-list-v3.json")
+	if err != nil {
+		t.Fatalf("Vendor list should be loaded without error: %s", err)
+	}
+	l, err := gvl.LoadCMPListFile("gvl/testdata/cmp-list.json")
+	if err != nil {
+		t.Fatalf("CMP list should be loaded without error: %s", err)
+	}
+
+	for _, data := range []*TCData{nil, {}} {
+		if len(Validate(data, ValidationOptions{})) > 0 || len(ValidateVendorList(data, vl)) > 0 || len(ValidateCMPList(data, l)) > 0 {
+			t.Errorf("TCData without Core String should have no issues: %+v", data)
+		}
+	}
+}
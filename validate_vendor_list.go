@@ -0,0 +1,83 @@
+package iabtcfv2
+
+import "github.com/SirDataFR/iabtcfv2/gvl"
+
+const (
+	ValidationCodeVendorListVersionMismatch  ValidationCode = "vendorListVersionMismatch"
+	ValidationCodeUnknownVendor              ValidationCode = "unknownVendor"
+	ValidationCodeDeletedVendor              ValidationCode = "deletedVendor"
+	ValidationCodeVendorWithoutLegIntPurpose ValidationCode = "vendorWithoutLegIntPurpose"
+	ValidationCodeNonFlexibleRestriction     ValidationCode = "nonFlexibleRestriction"
+	ValidationCodeUnknownDisclosedVendor     ValidationCode = "unknownDisclosedVendor"
+)
+
+// Returns the issues found in t against the vendors declared in vl, which should be the version VendorListVersion refers to
+// It reports consented vendors missing from or deleted in the list, legitimate interest signals of vendors that declare
+// no legitimate interest purpose, publisher restrictions requiring a legal basis for a purpose the vendor didn't declare
+// as flexible, including purposes it didn't declare at all, and disclosed vendors missing from the list
+func ValidateVendorList(t *TCData, vl *gvl.VendorList) []*ValidationIssue {
+	v := &validator{}
+	if t == nil || t.CoreString == nil || vl == nil {
+		return v.issues
+	}
+
+	c := t.CoreString
+	if c.VendorListVersion != vl.VendorListVersion {
+		v.add(ValidationCodeVendorListVersionMismatch, ValidationSeverityWarning, "VendorListVersion", 0, "vendor list version %d isn't %d", c.VendorListVersion, vl.VendorListVersion)
+	}
+
+	newIDSetFromEncoding(c.IsRangeEncoding, c.VendorsConsent, c.RangeEntries).Iterate(func(id int) bool {
+		v.listedVendor(vl, fieldVendorsConsent, id)
+		return true
+	})
+
+	newIDSetFromEncoding(c.IsRangeEncodingLI, c.VendorsLITransparency, c.RangeEntriesLI).Iterate(func(id int) bool {
+		vendor := v.listedVendor(vl, fieldVendorsLITransparency, id)
+		// A flexible purpose declared under consent can be switched to legitimate interest by the publisher
+		if vendor != nil && len(vendor.LegIntPurposes) == 0 && len(vendor.FlexiblePurposes) == 0 {
+			v.add(ValidationCodeVendorWithoutLegIntPurpose, ValidationSeverityWarning, fieldVendorsLITransparency, id, "vendor %d declares no legitimate interest purpose", id)
+		}
+		return true
+	})
+
+	for _, r := range c.PubRestrictions {
+		if r.RestrictionType != RestrictionTypeRequireConsent && r.RestrictionType != RestrictionTypeRequireLI {
+			continue
+		}
+		newIDSetFromEncoding(true, nil, r.RangeEntries).Iterate(func(id int) bool {
+			vendor := vl.Vendor(id)
+			if vendor == nil || vendor.IsDeleted() {
+				return true
+			}
+			if !vendor.IsFlexiblePurpose(r.PurposeId) {
+				v.add(ValidationCodeNonFlexibleRestriction, ValidationSeverityError, fieldPubRestrictions, id, "vendor %d didn't declare purpose %d as flexible for restriction %s", id, r.PurposeId, r.RestrictionType)
+			}
+			return true
+		})
+	}
+
+	if d := t.DisclosedVendors; d != nil {
+		newIDSetFromEncoding(d.IsRangeEncoding, d.DisclosedVendors, d.RangeEntries).Iterate(func(id int) bool {
+			if vl.Vendor(id) == nil {
+				v.add(ValidationCodeUnknownDisclosedVendor, ValidationSeverityWarning, fieldDisclosedVendors, id, "vendor %d isn't in vendor list %d", id, vl.VendorListVersion)
+			}
+			return true
+		})
+	}
+
+	return v.issues
+}
+
+// Returns the vendor with id, or nil with an issue if it isn't in the list or was deleted
+func (v *validator) listedVendor(vl *gvl.VendorList, field string, id int) *gvl.Vendor {
+	vendor := vl.Vendor(id)
+	if vendor == nil {
+		v.add(ValidationCodeUnknownVendor, ValidationSeverityError, field, id, "vendor %d isn't in vendor list %d", id, vl.VendorListVersion)
+		return nil
+	}
+	if vendor.IsDeleted() {
+		v.add(ValidationCodeDeletedVendor, ValidationSeverityError, field, id, "vendor %d was deleted on %s", id, vendor.DeletedDate.Format("2006-01-02"))
+		return nil
+	}
+	return vendor
+}
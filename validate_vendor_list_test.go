@@ -0,0 +1,66 @@
+package iabtcfv2
+
+import (
+	"testing"
+
+	"github.com/SirDataFR/iabtcfv2/gvl"
+)
+
+func TestValidateVendorList(t *testing.T) {
+	vl, err := gvl.LoadFile("gvl/testdata/vendor-list-v3.json")
+	if err != nil {
+		t.Fatalf("Vendor list should be loaded without error: %s", err)
+	}
+
+	data, err := NewTCDataBuilder().
+		WithGVLVersion(150).
+		ConsentPurposes(1, 2, 3).
+		ConsentVendors(1, 2, 3).
+		LIVendors(1, 3).
+		AddPubRestriction(2, RestrictionTypeRequireLI, 1, 3).
+		AddPubRestriction(3, RestrictionTypeNotAllowed, 2).
+		DiscloseVendors(1, 2, 3).
+		Build()
+	if err != nil {
+		t.Fatalf("TCData should be built without error: %s", err)
+	}
+
+	if issues := ValidateVendorList(data, vl); len(issues) > 0 {
+		t.Errorf("Valid TCData should have no issues: %+v", issues[0])
+	}
+
+	data, _ = NewTCDataBuilder().
+		WithGVLVersion(149).
+		ConsentVendors(1, 4, 999).
+		LIVendors(2, 3).
+		AddPubRestriction(7, RestrictionTypeRequireConsent, 1, 2).
+		AddPubRestriction(8, RestrictionTypeRequireLI, 3).
+		DiscloseVendors(1, 999).
+		Build()
+
+	expected := []struct {
+		code  ValidationCode
+		field string
+		id    int
+	}{
+		{ValidationCodeVendorListVersionMismatch, "VendorListVersion", 0},
+		{ValidationCodeDeletedVendor, fieldVendorsConsent, 4},
+		{ValidationCodeUnknownVendor, fieldVendorsConsent, 999},
+		{ValidationCodeNonFlexibleRestriction, fieldPubRestrictions, 2},
+		{ValidationCodeNonFlexibleRestriction, fieldPubRestrictions, 3},
+		{ValidationCodeUnknownDisclosedVendor, fieldDisclosedVendors, 999},
+	}
+
+	issues := ValidateVendorList(data, vl)
+	if len(issues) != len(expected) {
+		for _, i := range issues {
+			t.Logf("%+v", i)
+		}
+		t.Fatalf("Expected %d issues, got %d", len(expected), len(issues))
+	}
+	for i, e := range expected {
+		if issues[i].Code != e.code || issues[i].Field != e.field || issues[i].Id != e.id {
+			t.Errorf("Unexpected issue %d: %+v", i, issues[i])
+		}
+	}
+}
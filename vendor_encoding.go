@@ -0,0 +1,36 @@
+package iabtcfv2
+
+// vendorEncoding is the representation of a set of vendor ids in a segment,
+// either as a bit field or as range entries
+type vendorEncoding struct {
+	maxVendorId     int
+	isRangeEncoding bool
+	bitField        map[int]bool
+	rangeEntries    []*RangeEntry
+}
+
+// Returns the shortest encoding of vendor ids
+// Range encoding is only used when it takes strictly less bits than the bit field
+func newVendorEncoding(ids *IDSet) *vendorEncoding {
+	entries := ids.Ranges()
+
+	e := &vendorEncoding{maxVendorId: ids.Max()}
+
+	rangeBitSize := bitsNumEntries
+	for _, entry := range entries {
+		rangeBitSize += entry.getBitSize()
+	}
+
+	if rangeBitSize < e.maxVendorId {
+		e.isRangeEncoding = true
+		e.rangeEntries = entries
+	} else {
+		e.bitField = make(map[int]bool, ids.Len())
+		ids.Iterate(func(id int) bool {
+			e.bitField[id] = true
+			return true
+		})
+	}
+
+	return e
+}
@@ -0,0 +1,86 @@
+package iabtcfv2
+
+import (
+	"testing"
+)
+
+func TestSetVendorsConsent(t *testing.T) {
+	c := &CoreString{
+		Version:         2,
+		Created:         timeFromDeciSeconds(16431552000),
+		LastUpdated:     timeFromDeciSeconds(16431552000),
+		ConsentLanguage: "EN",
+		PublisherCC:     "FR",
+	}
+
+	var ids []int
+	for id := 1; id <= 100; id++ {
+		ids = append(ids, id)
+	}
+	c.SetVendorsConsent(append(ids, 500, 500)...)
+	if !c.IsRangeEncoding || c.MaxVendorId != 500 || c.NumEntries != 2 || len(c.RangeEntries) != 2 {
+		t.Errorf("Vendors 1 to 100 and 500 should use range encoding with 2 entries: %+v", c.RangeEntries)
+		return
+	}
+
+	if c.RangeEntries[0].StartVendorID != 1 || c.RangeEntries[0].EndVendorID != 100 || c.RangeEntries[1].StartVendorID != 500 {
+		t.Errorf("Range entries should be coalesced")
+	}
+
+	c.SetVendorsLITransparency(5, 3, 1)
+	if c.IsRangeEncodingLI || c.MaxVendorIdLI != 5 || !c.VendorsLITransparency[3] || c.VendorsLITransparency[2] {
+		t.Errorf("Vendors 1, 3 and 5 should use bit field encoding")
+		return
+	}
+
+	segment, err := DecodeCoreString(c.Encode())
+	if err != nil {
+		t.Errorf("Segment should be decoded without error: %s", err)
+		return
+	}
+
+	if !segment.IsVendorAllowed(50) || !segment.IsVendorAllowed(500) || segment.IsVendorAllowed(101) || !segment.IsVendorLIAllowed(5) || segment.IsVendorLIAllowed(4) {
+		t.Errorf("Vendors should be preserved by encoding")
+	}
+}
+
+func TestOptimizeVendorEncoding(t *testing.T) {
+	str := "COxR03kOxR1CqBcABCENAgCMAP_AAH_AAAqIF3EXySoGY2thI2YVFxBEIYwfJxyigMgChgQIsSwNQIeFLBoGLiAAHBGYJAQAGBAEEACBAQIkHGBMCQAAgAgBiRCMQEGMCzNIBIBAggEbY0FACCVmHkHSmZCY7064O__QLuIJEFQMAkSBAIACLECIQwAQDiAAAYAlAAABAhIaAAgIWBQEeAAAACAwAAgAAABBAAACAAQAAICIAAABAAAgAiAQAAAAGgIQAACBABACRIAAAEANCAAgiCEAQg4EAo4AAA.IF3EXySoGY2tho2YVFzBEIYwfJxyigMgShgQIsS0NQIeFLBoGPiAAHBGYJAQAGBAkkACBAQIsHGBMCQABgAgRiRCMQEGMDzNIBIBAggkbY0FACCVmnkHS3ZCY70-6u__QA"
+
+	data, err := Decode(str)
+	if err != nil {
+		t.Errorf("TC String should be decoded without error: %s", err)
+		return
+	}
+
+	data.CoreString.MaxVendorIdLI = 60
+	data.CoreString.IsRangeEncodingLI = false
+	data.CoreString.VendorsLITransparency = map[int]bool{}
+	for id := 1; id <= 60; id++ {
+		data.CoreString.VendorsLITransparency[id] = true
+	}
+	before := data.ToTCString()
+
+	data.OptimizeVendorEncoding()
+	if !data.CoreString.IsRangeEncodingLI || data.CoreString.NumEntriesLI != 1 {
+		t.Errorf("Vendors 1 to 60 should use range encoding with 1 entry")
+	}
+
+	result := data.ToTCString()
+	if len(result) >= len(before) {
+		t.Errorf("OptimizeVendorEncoding() should produce a shorter string: in = %s, out = %s", before, result)
+	}
+
+	optimized, err := Decode(result)
+	if err != nil {
+		t.Errorf("TC String should be decoded without error: %s", err)
+		return
+	}
+
+	for id := 1; id <= 800; id++ {
+		if optimized.IsVendorAllowed(id) != data.IsVendorAllowed(id) || optimized.IsVendorLIAllowed(id) != data.IsVendorLIAllowed(id) || optimized.DisclosedVendors.IsVendorDisclosed(id) != data.DisclosedVendors.IsVendorDisclosed(id) {
+			t.Errorf("Vendor %d should be preserved by OptimizeVendorEncoding()", id)
+			return
+		}
+	}
+}